@@ -0,0 +1,140 @@
+package main
+
+import (
+	"awesomeProject/internal/app"
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const apikeyUsage = `Использование: songs-api apikey <команда>
+
+Команды:
+  create -name ИМЯ [-user ID] [-scopes songs:read,songs:write]
+              создать ключ; сам ключ выводится один раз и нигде не хранится.
+              Ключ без -user действует с ролью viewer
+  list        показать ключи (без самих ключей)
+  revoke ID   отозвать ключ`
+
+// runAPIKey управляет ключами API в базе
+func runAPIKey(args []string, cfg *config.Config, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return errors.New(apikeyUsage)
+	}
+
+	db, err := app.OpenDB(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if cfg.AutoMigrate {
+		if err := app.MigrateUp(db, cfg.DBDriver, logger); err != nil {
+			return err
+		}
+	}
+	keys := repository.NewSQLAPIKeyRepository(db, cfg.DBDriver == config.DriverSQLite)
+	ctx := context.Background()
+
+	switch command := args[0]; command {
+	case "create":
+		return createAPIKey(ctx, keys, args[1:])
+	case "list":
+		return listAPIKeys(ctx, keys)
+	case "revoke":
+		if len(args) < 2 {
+			return errors.New(apikeyUsage)
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("некорректный ID ключа: %s", args[1])
+		}
+		if err := keys.Revoke(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("ключ %d не найден или уже отозван", id)
+			}
+			return err
+		}
+		fmt.Printf("ключ %d отозван\n", id)
+		return nil
+	default:
+		return fmt.Errorf("неизвестная команда %q\n\n%s", command, apikeyUsage)
+	}
+}
+
+func createAPIKey(ctx context.Context, keys repository.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	name := fs.String("name", "", "название ключа, например имя клиента")
+	scopes := fs.String("scopes", auth.ScopeRead, "права через запятую: songs:read, songs:write")
+	userID := fs.Int64("user", 0, "ID пользователя, от имени которого действует ключ")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return errors.New("не задано название ключа (-name)")
+	}
+
+	var scopeList []string
+	for _, scope := range strings.Split(*scopes, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if !auth.KnownScope(scope) {
+			return fmt.Errorf("неизвестное право %q", scope)
+		}
+		scopeList = append(scopeList, scope)
+	}
+	if len(scopeList) == 0 {
+		return errors.New("не задано ни одного права (-scopes)")
+	}
+
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	key := model.APIKey{Name: *name, Prefix: prefix, Scopes: scopeList}
+	if *userID > 0 {
+		key.UserID = userID
+	}
+	key, err = keys.Create(ctx, key, hash)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("ключ %d (%s) создан, права: %s\n", key.ID, key.Name, strings.Join(key.Scopes, ","))
+	fmt.Println("сохраните ключ, повторно он показан не будет:")
+	fmt.Println(secret)
+	return nil
+}
+
+func listAPIKeys(ctx context.Context, keys repository.APIKeyRepository) error {
+	list, err := keys.List(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tНАЗВАНИЕ\tПРЕФИКС\tПРАВА\tПОЛЬЗОВАТЕЛЬ\tСОЗДАН\tОТОЗВАН")
+	for _, key := range list {
+		revoked := "-"
+		if key.RevokedAt != nil {
+			revoked = key.RevokedAt.Format(time.RFC3339)
+		}
+		user := "-"
+		if key.UserID != nil {
+			user = strconv.FormatInt(*key.UserID, 10)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s…\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Prefix,
+			strings.Join(key.Scopes, ","), user, key.CreatedAt.Format(time.RFC3339), revoked)
+	}
+	return w.Flush()
+}
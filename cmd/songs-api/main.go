@@ -3,45 +3,228 @@ package main
 import (
 	_ "awesomeProject/docs" // Импорт сгенерированной документации
 	"awesomeProject/internal/app"
+	"awesomeProject/internal/auth"
 	"awesomeProject/internal/config"
 	"awesomeProject/internal/handler"
 	"awesomeProject/internal/logger"
+	"awesomeProject/internal/ratelimit"
+	"awesomeProject/internal/tracing"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
 // @title Songs API
 // @version 1.0
-// @description Это API для управления песнями и их текстами
+// @description Это API для управления песнями и их текстами.
+// @description Ошибки возвращаются в формате application/problem+json (RFC 7807) со стабильным полем code.
+// @description Клиенты, явно передающие Accept: application/json, получают ошибки в прежнем формате {status, message}.
+// @description Язык сообщений выбирается по заголовку Accept-Language (поддерживаются ru и en).
+// @description Маршруты /songs и /search требуют ключ API (X-API-Key) или JWT (Authorization: Bearer)
+// @description с правом songs:read для чтения и songs:write для изменения.
+// @description Роль пользователя, к которому привязан ключ или субъект токена, ограничивает изменения:
+// @description viewer только читает, editor добавляет песни и изменяет свои, admin изменяет любые и управляет пользователями.
+// @description Частота запросов ограничена по клиенту отдельно для поиска, чтения, изменения и администрирования, а до аутентификации — по IP;
+// @description текущий остаток сообщают заголовки RateLimit-*, при превышении возвращается 429 с Retry-After.
 // @host localhost:1323
 // @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT в формате "Bearer <токен>"; ключ API также можно передать как Bearer
 func main() {
-	logger := logger.NewLogger()
+	noMigrate := flag.Bool("no-migrate", false, "не применять миграции при запуске сервера (см. songs-api migrate)")
+	flag.Parse()
+
+	// До загрузки .env уровень и формат логов берутся только из окружения
+	bootLogger := logger.NewLogger(config.GetEnv("LOG_LEVEL", "info"), config.GetEnv("LOG_FORMAT", logger.FormatJSON))
+	config := config.NewConfig(bootLogger)
+	logger := logger.NewLogger(config.LogLevel, config.LogFormat)
 	logger.Info("Starting...")
-	config := config.NewConfig(logger)
+	if *noMigrate {
+		config.AutoMigrate = false
+	}
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Args()[1:], config, logger); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "user" {
+		if err := runUser(flag.Args()[1:], config, logger); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "apikey" {
+		if err := runAPIKey(flag.Args()[1:], config, logger); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(config, logger); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run запускает сервер и возвращает управление после его остановки. Выход
+// с ошибкой происходит в main, уже после отложенных закрытия приложения и
+// сброса трассировки.
+func run(config *config.Config, logger *slog.Logger) error {
+	shutdownTracing, err := tracing.Setup(context.Background(), config.TracingExporter, config.TracingSampleRatio, logger)
+	if err != nil {
+		logger.Error("Failed to configure tracing", "error", err)
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush spans", "error", err)
+		}
+	}()
+
 	appInstance, err := app.NewApp(config, logger)
 	if err != nil {
 		logger.Error("Ошибка инициализации приложения", "error", err)
-		fmt.Println(err)
-		return
+		return err
 	}
-	defer appInstance.DB.Close()
-	h := handler.NewHandler(appInstance.Service, logger)
+	defer appInstance.Close()
+	h := handler.NewHandler(appInstance.Service, config.DefaultLanguage, logger)
 	e := echo.New()
+	e.HTTPErrorHandler = h.HTTPErrorHandler
+	// По умолчанию echo верит X-Forwarded-For, и клиент мог бы выдать себя
+	// за любой IP, обходя лимит запросов
+	if config.TrustProxyHeaders {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+	e.Use(handler.RequestID())
+	e.Use(tracing.Middleware())
+	e.Use(handler.AccessLog(logger))
+	e.Use(appInstance.Metrics.Middleware())
+	e.Use(handler.ResolveError())
+	// Паника в обработчике превращается в ошибку 500, которую видят журнал
+	// доступа и метрики, вместо обрыва соединения
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
+		DisableErrorHandler: true,
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			logger.ErrorContext(c.Request().Context(), "Recovered from panic", "error", err, "stack", string(stack))
+			return err
+		},
+	}))
 	logger.Debug("Registering routes")
-	e.GET("/songs", h.GetHandler)
-	e.POST("/songs", h.PostHandler)
-	e.DELETE("/songs", h.DeleteHandler)
-	e.PATCH("/songs", h.PatchHandler)
-	e.GET("/songs/:id/verses", h.GetVersesHandler)
-	e.GET("/songs/verses/search", h.SearchVersesHandler)
+	store := ratelimit.NewMemoryStore()
+	limit := func(group string, perMinute, burst int) []echo.MiddlewareFunc {
+		if !config.RateLimitEnabled {
+			return nil
+		}
+		return []echo.MiddlewareFunc{handler.RateLimit(store, group, ratelimit.Limit{PerMinute: perMinute, Burst: burst}, logger)}
+	}
+	// Маршруты песен требуют права на чтение или запись; проверки
+	// состояния, метрики и документация остаются открытыми. Лимит по IP
+	// стоит перед Authenticate, лимиты групп — после него, по вызывающему.
+	var authn, readScope, writeScope []echo.MiddlewareFunc
+	if appInstance.Auth != nil {
+		authn = append(limit(handler.RateLimitAuth, config.RateLimitAuthPerMinute, config.RateLimitAuthBurst),
+			handler.Authenticate(appInstance.Auth, logger))
+		readScope = []echo.MiddlewareFunc{handler.RequireScope(auth.ScopeRead)}
+		writeScope = []echo.MiddlewareFunc{handler.RequireScope(auth.ScopeWrite)}
+	}
+	// Поиск перебирает тексты песен, поэтому лимит у него строже, чем у чтения
+	search := slices.Concat(authn, readScope, limit(handler.RateLimitSearch, config.RateLimitSearchPerMinute, config.RateLimitSearchBurst))
+	read := slices.Concat(authn, readScope, limit(handler.RateLimitRead, config.RateLimitReadPerMinute, config.RateLimitReadBurst))
+	write := slices.Concat(authn, writeScope, limit(handler.RateLimitWrite, config.RateLimitWritePerMinute, config.RateLimitWriteBurst))
+	e.GET("/songs", h.GetHandler, read...)
+	e.POST("/songs", h.PostHandler, write...)
+	e.GET("/songs/:id", h.GetSongHandler, read...)
+	e.PUT("/songs/:id", h.PutHandler, write...)
+	e.PATCH("/songs/:id", h.PatchHandler, write...)
+	e.DELETE("/songs/:id", h.DeleteHandler, write...)
+	// Устаревшие маршруты с ID в query-параметре
+	e.DELETE("/songs", h.LegacyDeleteHandler, write...)
+	e.PATCH("/songs", h.LegacyPatchHandler, write...)
+	e.GET("/songs/:id/verses", h.GetVersesHandler, read...)
+	e.GET("/songs/verses/search", h.SearchVersesHandler, search...)
+	e.GET("/search", h.SearchHandler, search...)
+	// Управление пользователями без аутентификации не имеет смысла: роли
+	// проверяются только у аутентифицированных запросов
+	if appInstance.Auth != nil {
+		adminHandler := handler.NewAdminHandler(appInstance.Users, config.DefaultLanguage)
+		admin := e.Group("/admin", slices.Concat(authn, limit(handler.RateLimitAdmin, config.RateLimitAdminPerMinute, config.RateLimitAdminBurst))...)
+		admin.GET("/users", adminHandler.ListUsersHandler)
+		admin.POST("/users", adminHandler.CreateUserHandler)
+		admin.GET("/users/:id", adminHandler.GetUserHandler)
+		admin.PUT("/users/:id/role", adminHandler.AssignRoleHandler)
+		admin.DELETE("/users/:id", adminHandler.DeleteUserHandler)
+		admin.GET("/roles", adminHandler.ListRolesHandler)
+	}
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
-	logger.Info("Server starting on :1323    port")
-	if err := e.Start(":1323"); err != nil {
-		logger.Error("Server falied to start", "error", err)
-		e.Logger.Fatal(err)
+	healthHandler := handler.NewHealthHandler(appInstance.Health)
+	e.GET("/healthz", healthHandler.LivenessHandler)
+	e.GET("/readyz", healthHandler.ReadinessHandler)
+	e.GET("/metrics", echo.WrapHandler(appInstance.Metrics.Handler()))
+	e.HideBanner = true
+	e.Server.ReadTimeout = config.HTTPReadTimeout
+	e.Server.WriteTimeout = config.HTTPWriteTimeout
+	e.Server.IdleTimeout = config.HTTPIdleTimeout
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Server starting", "addr", config.HTTPAddr)
+		if err := e.Start(config.HTTPAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			logger.Error("Server failed to start", "error", err)
+			return err
+		}
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received, draining in-flight requests", "timeout", config.ShutdownTimeout)
+	}
+	stop()
+	appInstance.Health.SetShuttingDown()
+	if config.ShutdownDelay > 0 {
+		logger.Info("Waiting before shutdown so that load balancers observe readiness failure", "delay", config.ShutdownDelay)
+		time.Sleep(config.ShutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server shutdown did not complete in time", "error", err)
+		return err
 	}
+	logger.Info("Server stopped")
+	return nil
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"awesomeProject/internal/app"
+	"awesomeProject/internal/config"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+const migrateUsage = `Использование: songs-api migrate <команда>
+
+Команды:
+  up          применить все новые миграции
+  down [N]    откатить N последних миграций (по умолчанию одну)
+  to N        перейти к версии N (вперёд или назад)
+  status      показать текущую и последнюю доступную версию
+  force N     пометить версию N как применённую и снять флаг dirty`
+
+// runMigrate выполняет подкоманду migrate над встроенными миграциями
+func runMigrate(args []string, cfg *config.Config, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := app.OpenDB(cfg, logger)
+	if err != nil {
+		return err
+	}
+	m, err := app.NewMigrate(db, cfg.DBDriver)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	defer m.Close()
+
+	switch command := args[0]; command {
+	case "up":
+		err = m.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("некорректное число шагов: %s", args[1])
+			}
+		}
+		err = m.Steps(-steps)
+	case "to", "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, convErr := strconv.ParseUint(args[1], 10, 64)
+		if convErr != nil {
+			return fmt.Errorf("некорректный номер версии: %s", args[1])
+		}
+		if command == "to" {
+			err = m.Migrate(uint(version))
+		} else {
+			err = m.Force(int(version))
+		}
+	case "status":
+		return printStatus(m, cfg.DBDriver)
+	default:
+		return fmt.Errorf("неизвестная команда %q\n\n%s", command, migrateUsage)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		logger.Info("No migrations to apply")
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка выполнения миграции: %v", err)
+	}
+	return printStatus(m, cfg.DBDriver)
+}
+
+func printStatus(m *migrate.Migrate, driverName string) error {
+	latest, err := app.LatestVersion(driverName)
+	if err != nil {
+		return err
+	}
+	version, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		fmt.Printf("версия: нет (миграции не применялись), последняя: %d\n", latest)
+		return nil
+	case err != nil:
+		return fmt.Errorf("ошибка получения версии: %v", err)
+	}
+	fmt.Printf("версия: %d, последняя: %d, dirty: %t\n", version, latest, dirty)
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"awesomeProject/internal/app"
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const userUsage = `Использование: songs-api user <команда>
+
+Команды:
+  create -name ИМЯ [-role viewer|editor|admin]
+                  создать пользователя (по умолчанию с ролью viewer)
+  list            показать пользователей
+  role ID РОЛЬ    назначить роль
+  delete ID       удалить пользователя и отозвать его ключи API
+
+Первого администратора создайте здесь, затем выпустите для него ключ:
+  songs-api apikey create -name admin -user ID -scopes songs:read,songs:write`
+
+// runUser управляет пользователями в базе; нужна прежде всего для создания
+// первого администратора, дальше доступны маршруты /admin
+func runUser(args []string, cfg *config.Config, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return errors.New(userUsage)
+	}
+
+	db, err := app.OpenDB(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if cfg.AutoMigrate {
+		if err := app.MigrateUp(db, cfg.DBDriver, logger); err != nil {
+			return err
+		}
+	}
+	users := repository.NewSQLUserRepository(db, cfg.DBDriver == config.DriverSQLite)
+	ctx := context.Background()
+
+	switch command := args[0]; command {
+	case "create":
+		fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+		name := fs.String("name", "", "имя пользователя; совпадает с sub в JWT")
+		role := fs.String("role", auth.RoleViewer, "роль: viewer, editor или admin")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if strings.TrimSpace(*name) == "" {
+			return errors.New("не задано имя пользователя (-name)")
+		}
+		if !auth.KnownRole(*role) {
+			return fmt.Errorf("неизвестная роль %q", *role)
+		}
+		user, err := users.Create(ctx, model.User{Name: strings.TrimSpace(*name), Role: *role})
+		if errors.Is(err, repository.ErrConflict) {
+			return fmt.Errorf("пользователь %q уже существует", *name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("пользователь %d (%s) создан, роль: %s\n", user.ID, user.Name, user.Role)
+		return nil
+	case "list":
+		list, err := users.List(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tИМЯ\tРОЛЬ\tСОЗДАН")
+		for _, user := range list {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", user.ID, user.Name, user.Role, user.CreatedAt.Format(time.RFC3339))
+		}
+		return w.Flush()
+	case "role", "delete":
+		if len(args) < 2 || (command == "role" && len(args) < 3) {
+			return errors.New(userUsage)
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("некорректный ID пользователя: %s", args[1])
+		}
+		if command == "role" {
+			if !auth.KnownRole(args[2]) {
+				return fmt.Errorf("неизвестная роль %q", args[2])
+			}
+			_, err = users.SetRole(ctx, id, args[2])
+		} else {
+			err = users.Delete(ctx, id)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("пользователь %d не найден", id)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("пользователь %d: готово\n", id)
+		return nil
+	default:
+		return fmt.Errorf("неизвестная команда %q\n\n%s", command, userUsage)
+	}
+}
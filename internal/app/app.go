@@ -1,82 +1,142 @@
 package app
 
 import (
-	"awesomeProject/internal/config"
+	"awesomeProject/internal/auth"
+	configpkg "awesomeProject/internal/config"
+	"awesomeProject/internal/health"
+	"awesomeProject/internal/metrics"
+	"awesomeProject/internal/musicinfo"
+	"awesomeProject/internal/repository"
 	"awesomeProject/internal/service"
+	"crypto/rand"
 	"database/sql"
-	"errors"
 	"fmt"
 	"log/slog"
-	"os"
-
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/lib/pq"
 )
 
 type App struct {
 	DB      *sql.DB
 	Service service.SongServiceInterface
-	Logger  *slog.Logger
+	Users   service.UserServiceInterface
+	Health  *health.Checker
+	Metrics *metrics.Metrics
+	// Auth равен nil, если аутентификация отключена (AUTH_ENABLED=false)
+	Auth   *auth.Authenticator
+	Logger *slog.Logger
 }
 
-func NewApp(config *config.Config, logger *slog.Logger) (*App, error) {
+func NewApp(config *configpkg.Config, logger *slog.Logger) (*App, error) {
 	logger.Info("Initializing application", "config", config)
-	dir, err := os.Getwd()
+	db, err := OpenDB(config, logger)
 	if err != nil {
-		logger.Error("Failed to get working directory", "error", err)
+		return nil, err
+	}
+
+	if config.AutoMigrate {
+		if err := MigrateUp(db, config.DBDriver, logger); err != nil {
+			db.Close()
+			return nil, err
+		}
 	} else {
-		logger.Debug("Current working directory", "dir", dir)
+		logger.Info("Automatic migrations are disabled, run \"songs-api migrate up\" separately")
 	}
-	psqlInfo := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		config.DBHost, config.DBPort, config.DBUser, config.DBPassword, config.DBName)
-	logger.Debug("Connecting to database", "connection_string", psqlInfo)
 
-	db, err := sql.Open("postgres", psqlInfo)
-	if err != nil {
-		logger.Error("Failed to open database connection", "error", err)
-		return nil, fmt.Errorf("ошибка подключения к базе данных: %v", err)
+	var repo repository.SongRepository
+	if config.DBDriver == configpkg.DriverSQLite {
+		repo = repository.NewSQLiteSongRepository(db, config.FuzzyThreshold)
+	} else {
+		repo = repository.NewPostgresSongRepository(db, config.FuzzyThreshold)
 	}
-	logger.Info("Database connection opened successfully")
 
-	err = db.Ping()
-	if err != nil {
-		logger.Error("Failed to ping database", "error", err)
-		return nil, fmt.Errorf("ошибка проверки соединения: %v", err)
+	var infoClient musicinfo.Client
+	if config.MusicInfoURL != "" {
+		infoClient = musicinfo.NewHTTPClient(config.MusicInfoURL, config.MusicInfoTimeout, config.MusicInfoRetries, logger)
+		logger.Info("Music info client configured", "url", configpkg.RedactURL(config.MusicInfoURL))
+	} else {
+		logger.Warn("MUSIC_INFO_URL is not set, new songs will not be enriched")
 	}
-	logger.Info("Database ping successful")
 
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	timeouts := service.Timeouts{
+		Read:   config.DBReadTimeout,
+		Write:  config.DBWriteTimeout,
+		Search: config.DBSearchTimeout,
+	}
+	appMetrics := metrics.New(db)
+	users := repository.NewSQLUserRepository(db, config.DBDriver == configpkg.DriverSQLite)
+	userService := service.NewUserService(users, timeouts, appMetrics, logger)
+	cursorSecret, err := cursorSecret(config, logger)
 	if err != nil {
-		logger.Error("Failed to create migration driver", "error", err)
-		return nil, fmt.Errorf("ошибка создания драйвера миграций: %v", err)
+		db.Close()
+		return nil, err
 	}
-	logger.Debug("Migration driver created successfully")
+	service := service.NewSongService(repo, infoClient, service.NewCursorCodec(cursorSecret), timeouts, appMetrics, logger)
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	latestVersion, err := LatestVersion(config.DBDriver)
 	if err != nil {
-		logger.Error("Failed to initialize migrations", "error", err)
-		return nil, fmt.Errorf("ошибка инициализации миграций: %v", err)
+		db.Close()
+		return nil, err
 	}
-	logger.Debug("Migrations initialized successfully")
+	checker := health.NewChecker(db, latestVersion, config.ReadinessTimeout, logger)
 
-	err = m.Up()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		logger.Error("Failed to apply migrations", "error", err)
-		return nil, fmt.Errorf("ошибка применения миграций: %v", err)
+	authenticator, err := newAuthenticator(db, users, config, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
-	logger.Info("Migrations applied successfully or no change")
 
-	service := service.NewSongService(db, logger)
 	return &App{
 		DB:      db,
 		Service: service,
+		Users:   userService,
+		Health:  checker,
+		Metrics: appMetrics,
+		Auth:    authenticator,
 		Logger:  logger,
 	}, nil
 }
+
+// cursorSecret возвращает ключ подписи курсоров из настроек или случайный
+func cursorSecret(config *configpkg.Config, logger *slog.Logger) ([]byte, error) {
+	if config.CursorSecret != "" {
+		return []byte(config.CursorSecret), nil
+	}
+	logger.Warn("CURSOR_SECRET is not set, pagination cursors will be invalidated on restart")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("ошибка генерации ключа курсоров: %v", err)
+	}
+	return secret, nil
+}
+
+// newAuthenticator настраивает проверку ключей API и, если задан JWKS, JWT
+func newAuthenticator(db *sql.DB, users repository.UserRepository, config *configpkg.Config, logger *slog.Logger) (*auth.Authenticator, error) {
+	if !config.AuthEnabled {
+		logger.Warn("Authentication is disabled, /songs routes are open to everyone")
+		return nil, nil
+	}
+
+	var verifier *auth.JWTVerifier
+	if config.JWTJWKSFile != "" {
+		var err error
+		verifier, err = auth.LoadJWKS(config.JWTJWKSFile, config.JWTIssuer, config.JWTAudience)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("JWT authentication configured", "jwks_file", config.JWTJWKSFile)
+	} else {
+		logger.Info("JWT_JWKS_FILE is not set, only API keys are accepted")
+	}
+
+	keys := repository.NewSQLAPIKeyRepository(db, config.DBDriver == configpkg.DriverSQLite)
+	return auth.NewAuthenticator(keys, users, verifier), nil
+}
+
+// Close освобождает ресурсы приложения; вызывается после остановки HTTP-сервера
+func (a *App) Close() error {
+	a.Logger.Info("Closing database connection pool")
+	if err := a.DB.Close(); err != nil {
+		a.Logger.Error("Failed to close database", "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	configpkg "awesomeProject/internal/config"
+	"awesomeProject/internal/repository/migrations"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/XSAM/otelsql"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenDB открывает соединение с хранилищем, выбранным в DB_DRIVER, не применяя миграции
+func OpenDB(config *configpkg.Config, logger *slog.Logger) (*sql.DB, error) {
+	if config.DBDriver == configpkg.DriverSQLite {
+		return openSQLite(config, logger)
+	}
+	return openPostgres(config, logger)
+}
+
+// tracingOptions настраивает спаны запросов к базе: спан с текстом запроса
+// создаётся только внутри уже начатой трассы, чтобы миграции и служебные
+// обращения пула не порождали отдельные трассы
+func tracingOptions(system attribute.KeyValue) []otelsql.Option {
+	return []otelsql.Option{
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanFromContext(ctx).SpanContext().IsValid()
+			},
+		}),
+	}
+}
+
+func openPostgres(config *configpkg.Config, logger *slog.Logger) (*sql.DB, error) {
+	const dsn = "host=%s port=%d user=%s password=%s dbname=%s sslmode=disable"
+	psqlInfo := fmt.Sprintf(dsn, config.DBHost, config.DBPort, config.DBUser, config.DBPassword, config.DBName)
+	logger.Debug("Connecting to database",
+		"connection_string", fmt.Sprintf(dsn, config.DBHost, config.DBPort, config.DBUser, "[REDACTED]", config.DBName))
+
+	db, err := otelsql.Open("postgres", psqlInfo, tracingOptions(semconv.DBSystemPostgreSQL)...)
+	if err != nil {
+		logger.Error("Failed to open database connection", "error", err)
+		return nil, fmt.Errorf("ошибка подключения к базе данных: %v", err)
+	}
+	logger.Info("Database connection opened successfully")
+
+	err = db.Ping()
+	if err != nil {
+		logger.Error("Failed to ping database", "error", err)
+		db.Close()
+		return nil, fmt.Errorf("ошибка проверки соединения: %v", err)
+	}
+	logger.Info("Database ping successful")
+	return db, nil
+}
+
+// openSQLite открывает файл встроенной базы. SQLite не поддерживает
+// параллельную запись, поэтому пул ограничен одним соединением; это же
+// позволяет использовать ":memory:". Внешние ключи в SQLite по умолчанию
+// не проверяются и включаются явно.
+func openSQLite(config *configpkg.Config, logger *slog.Logger) (*sql.DB, error) {
+	logger.Debug("Opening SQLite database", "path", config.SQLitePath)
+	db, err := otelsql.Open("sqlite", config.SQLitePath+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)", tracingOptions(semconv.DBSystemSqlite)...)
+	if err != nil {
+		logger.Error("Failed to open SQLite database", "error", err)
+		return nil, fmt.Errorf("ошибка открытия базы данных SQLite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	err = db.Ping()
+	if err != nil {
+		logger.Error("Failed to ping database", "error", err)
+		db.Close()
+		return nil, fmt.Errorf("ошибка проверки соединения: %v", err)
+	}
+	logger.Info("SQLite database opened successfully", "path", config.SQLitePath)
+	return db, nil
+}
+
+// migrationSource возвращает встроенные в бинарный файл миграции для хранилища
+func migrationSource(driverName string) (source.Driver, error) {
+	var files fs.FS = migrations.Postgres
+	dir := "postgres"
+	if driverName == configpkg.DriverSQLite {
+		files, dir = migrations.SQLite, "sqlite"
+	}
+	src, err := iofs.New(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения встроенных миграций: %v", err)
+	}
+	return src, nil
+}
+
+// NewMigrate создаёт мигратор для открытой базы. Закрытие мигратора
+// закрывает и переданное соединение.
+func NewMigrate(db *sql.DB, driverName string) (*migrate.Migrate, error) {
+	src, err := migrationSource(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	var driver database.Driver
+	if driverName == configpkg.DriverSQLite {
+		driver, err = sqlite.WithInstance(db, &sqlite.Config{})
+	} else {
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания драйвера миграций: %v", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driverName, driver)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации миграций: %v", err)
+	}
+	return m, nil
+}
+
+// MigrateUp применяет все новые миграции
+func MigrateUp(db *sql.DB, driverName string, logger *slog.Logger) error {
+	m, err := NewMigrate(db, driverName)
+	if err != nil {
+		logger.Error("Failed to initialize migrations", "error", err)
+		return err
+	}
+	logger.Debug("Migrations initialized successfully")
+
+	err = m.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		logger.Error("Failed to apply migrations", "error", err)
+		return fmt.Errorf("ошибка применения миграций: %v", err)
+	}
+	logger.Info("Migrations applied successfully or no change")
+	return nil
+}
+
+// LatestVersion возвращает номер последней встроенной миграции
+func LatestVersion(driverName string) (uint, error) {
+	src, err := migrationSource(driverName)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения встроенных миграций: %v", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("ошибка чтения встроенных миграций: %v", err)
+		}
+		version = next
+	}
+}
@@ -0,0 +1,173 @@
+// Package auth проверяет ключи API и JWT и описывает права вызывающего
+package auth
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Права доступа к песням
+const (
+	ScopeRead  = "songs:read"
+	ScopeWrite = "songs:write"
+)
+
+// Роли пользователей. Права (scopes) ограничивают маршруты, роль — то,
+// какие песни можно изменять.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Способы аутентификации
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// apiKeyPrefix отличает ключи API от JWT в заголовке Authorization
+const apiKeyPrefix = "songs_"
+
+var (
+	// ErrInvalidCredentials — ключ или токен не распознан, отозван или просрочен
+	ErrInvalidCredentials = errors.New("недействительные учётные данные")
+	// ErrMethodDisabled — способ аутентификации не настроен
+	ErrMethodDisabled = errors.New("способ аутентификации не настроен")
+)
+
+// KnownScope сообщает, поддерживается ли право
+func KnownScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeWrite
+}
+
+// KnownRole сообщает, существует ли роль
+func KnownRole(role string) bool {
+	return role == RoleViewer || role == RoleEditor || role == RoleAdmin
+}
+
+// Principal — аутентифицированный вызывающий. UserID равен нулю, если ключ
+// не привязан к пользователю или субъекта токена нет среди пользователей;
+// такой вызывающий получает роль viewer.
+type Principal struct {
+	Subject string
+	Method  string
+	Scopes  []string
+	UserID  int64
+	Role    string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает вызывающего, сохранённого промежуточным обработчиком аутентификации
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// IsAPIKey отличает ключ API от JWT
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// GenerateAPIKey создаёт случайный ключ. Возвращается сам ключ (показывается
+// пользователю один раз), его префикс для опознания и хэш для хранения.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("ошибка генерации ключа: %v", err)
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:len(apiKeyPrefix)+6], HashAPIKey(key), nil
+}
+
+// HashAPIKey возвращает SHA-256 ключа. Ключи случайные и длинные, поэтому
+// медленное хэширование, как для паролей, не требуется.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticator проверяет ключи API по хранилищу и JWT по локальному JWKS,
+// а затем определяет роль вызывающего по таблице пользователей
+type Authenticator struct {
+	keys  repository.APIKeyRepository
+	users repository.UserRepository
+	jwt   *JWTVerifier // nil, если JWKS не задан
+}
+
+func NewAuthenticator(keys repository.APIKeyRepository, users repository.UserRepository, jwt *JWTVerifier) *Authenticator {
+	return &Authenticator{keys: keys, users: users, jwt: jwt}
+}
+
+// Authenticate определяет вызывающего по ключу API или JWT
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	if IsAPIKey(token) {
+		return a.authenticateAPIKey(ctx, token)
+	}
+	if a.jwt == nil {
+		return Principal{}, ErrMethodDisabled
+	}
+	principal, err := a.jwt.Verify(token)
+	if err != nil {
+		return Principal{}, err
+	}
+	// Субъект токена сопоставляется с пользователем по имени
+	user, err := a.users.FindByName(ctx, principal.Subject)
+	return withUser(principal, user, err)
+}
+
+func (a *Authenticator) authenticateAPIKey(ctx context.Context, token string) (Principal, error) {
+	key, err := a.keys.FindByHash(ctx, HashAPIKey(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return Principal{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+	principal := principalFromKey(key)
+	if key.UserID == nil {
+		return withUser(principal, model.User{}, repository.ErrNotFound)
+	}
+	user, err := a.users.Get(ctx, *key.UserID)
+	return withUser(principal, user, err)
+}
+
+// withUser дополняет вызывающего ролью найденного пользователя
+func withUser(principal Principal, user model.User, err error) (Principal, error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		principal.Role = RoleViewer
+		return principal, nil
+	case err != nil:
+		return Principal{}, err
+	}
+	principal.UserID = user.ID
+	principal.Role = user.Role
+	return principal, nil
+}
+
+func principalFromKey(key model.APIKey) Principal {
+	return Principal{
+		Subject: fmt.Sprintf("api_key:%d", key.ID),
+		Method:  MethodAPIKey,
+		Scopes:  key.Scopes,
+	}
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/repository/migrations"
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestDB создаёт базу SQLite в памяти со встроенными миграциями
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	files, err := fs.Glob(migrations.SQLite, "sqlite/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		if _, err := db.Exec(string(must(fs.ReadFile(migrations.SQLite, name)))); err != nil {
+			t.Fatalf("migration %s: %v", name, err)
+		}
+	}
+	return db
+}
+
+func TestAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	keys := repository.NewSQLAPIKeyRepository(db, true)
+	users := repository.NewSQLUserRepository(db, true)
+
+	editor, err := users.Create(ctx, model.User{Name: "alice", Role: RoleEditor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	createKey := func(scopes []string, userID *int64) (string, model.APIKey) {
+		key, prefix, hash, err := GenerateAPIKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		created, err := keys.Create(ctx, model.APIKey{Name: "test", Prefix: prefix, Scopes: scopes, UserID: userID}, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key, created
+	}
+	userKey, _ := createKey([]string{ScopeRead, ScopeWrite}, &editor.ID)
+	anonymousKey, _ := createKey([]string{ScopeRead}, nil)
+	revokedKey, revoked := createKey([]string{ScopeRead}, nil)
+	if err := keys.Revoke(ctx, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey := must(rsaTestKey())
+	verifier, err := LoadJWKS(writeJWKS(t, rsaKey), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := func(sub string) string {
+		return sign(t, jwt.SigningMethodRS256, "rs", rsaKey, jwt.MapClaims{"sub": sub, "exp": time.Now().Add(time.Hour).Unix(), "scope": ScopeRead})
+	}
+
+	tests := []struct {
+		name          string
+		authenticator *Authenticator
+		token         string
+		want          Principal
+		err           error
+	}{
+		{"API key of a user", NewAuthenticator(keys, users, verifier), userKey,
+			Principal{Method: MethodAPIKey, Scopes: []string{ScopeRead, ScopeWrite}, UserID: editor.ID, Role: RoleEditor}, nil},
+		{"API key without a user is a viewer", NewAuthenticator(keys, users, verifier), anonymousKey,
+			Principal{Method: MethodAPIKey, Scopes: []string{ScopeRead}, Role: RoleViewer}, nil},
+		{"revoked API key", NewAuthenticator(keys, users, verifier), revokedKey, Principal{}, ErrInvalidCredentials},
+		{"unknown API key", NewAuthenticator(keys, users, verifier), apiKeyPrefix + "unknown", Principal{}, ErrInvalidCredentials},
+		{"JWT of a user", NewAuthenticator(keys, users, verifier), token("alice"),
+			Principal{Subject: "alice", Method: MethodJWT, Scopes: []string{ScopeRead}, UserID: editor.ID, Role: RoleEditor}, nil},
+		{"JWT of an unknown subject is a viewer", NewAuthenticator(keys, users, verifier), token("bob"),
+			Principal{Subject: "bob", Method: MethodJWT, Scopes: []string{ScopeRead}, Role: RoleViewer}, nil},
+		{"JWT without JWKS", NewAuthenticator(keys, users, nil), token("alice"), Principal{}, ErrMethodDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.authenticator.Authenticate(ctx, tt.token)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Authenticate: err = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if tt.want.Method == MethodAPIKey {
+				// Субъект ключа содержит его ID из базы
+				tt.want.Subject = got.Subject
+			}
+			if got.Subject != tt.want.Subject || got.Method != tt.want.Method || got.UserID != tt.want.UserID ||
+				got.Role != tt.want.Role || !slices.Equal(got.Scopes, tt.want.Scopes) {
+				t.Errorf("principal = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwk — ключ из JWKS (RFC 7517). Поддерживаются симметричные ключи (kty=oct)
+// для HS256 и открытые ключи RSA для RS256.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type verificationKey struct {
+	alg string
+	key interface{}
+}
+
+// JWTVerifier проверяет подпись и стандартные поля JWT
+type JWTVerifier struct {
+	keys     map[string]verificationKey // по kid
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+// LoadJWKS читает JWKS из файла. issuer и audience проверяются, если заданы.
+func LoadJWKS(path, issuer, audience string) (*JWTVerifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения JWKS: %v", err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JWKS: %v", err)
+	}
+
+	v := &JWTVerifier{
+		keys:     make(map[string]verificationKey),
+		issuer:   issuer,
+		audience: audience,
+		leeway:   30 * time.Second,
+	}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			return nil, fmt.Errorf("ключ %d в JWKS: %v", i, err)
+		}
+		if _, ok := v.keys[k.Kid]; ok {
+			return nil, fmt.Errorf("ключ %d в JWKS: повторяется kid %q", i, k.Kid)
+		}
+		v.keys[k.Kid] = key
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.New("JWKS не содержит ключей для проверки подписи")
+	}
+	return v, nil
+}
+
+func parseJWK(k jwk) (verificationKey, error) {
+	switch k.Kty {
+	case "oct":
+		if k.Alg != "" && k.Alg != "HS256" {
+			return verificationKey{}, fmt.Errorf("алгоритм %s не поддерживается", k.Alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) < 32 {
+			return verificationKey{}, errors.New("секрет HS256 должен быть не короче 32 байт в base64url")
+		}
+		return verificationKey{alg: "HS256", key: secret}, nil
+	case "RSA":
+		if k.Alg != "" && k.Alg != "RS256" {
+			return verificationKey{}, fmt.Errorf("алгоритм %s не поддерживается", k.Alg)
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+			return verificationKey{}, errors.New("некорректный открытый ключ RSA")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return verificationKey{alg: "RS256", key: pub}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("тип ключа %q не поддерживается", k.Kty)
+	}
+}
+
+// claims — поля токена, из которых берутся права: scope (строка через пробел,
+// RFC 8693) или scp (массив)
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope"`
+	Scp   []string `json:"scp"`
+}
+
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.leeway),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	var c claims
+	if _, err := jwt.ParseWithClaims(token, &c, v.keyFor, opts...); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	scopes := strings.Fields(c.Scope)
+	scopes = append(scopes, c.Scp...)
+	return Principal{Subject: c.Subject, Method: MethodJWT, Scopes: scopes}, nil
+}
+
+// keyFor выбирает ключ по kid. Алгоритм токена обязан совпадать с типом
+// ключа, иначе открытый ключ RSA можно было бы подсунуть как секрет HS256.
+func (v *JWTVerifier) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := v.keys[kid]
+	if !ok && kid == "" && len(v.keys) == 1 {
+		for _, only := range v.keys {
+			key, ok = only, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("неизвестный kid %q", kid)
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("алгоритм %s не соответствует ключу", token.Method.Alg())
+	}
+	return key.key, nil
+}
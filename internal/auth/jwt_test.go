@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var hsSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// writeJWKS сохраняет JWKS с ключом HS256 (kid "hs") и открытым ключом
+// RS256 (kid "rs") и возвращает путь к файлу
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey) string {
+	t.Helper()
+	set := map[string][]jwk{"keys": {
+		{Kty: "oct", Kid: "hs", Alg: "HS256", K: base64.RawURLEncoding.EncodeToString(hsSecret)},
+		{Kty: "RSA", Kid: "rs", Alg: "RS256", Use: "sig",
+			N: base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{Kty: "RSA", Kid: "enc", Use: "enc"},
+	}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsaTestKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := LoadJWKS(writeJWKS(t, rsaKey), "https://issuer.example", "songs-api")
+	if err != nil {
+		t.Fatalf("LoadJWKS: %v", err)
+	}
+	// Открытый ключ в PEM — то, что атакующий подставил бы как секрет HS256
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: must(x509.MarshalPKIXPublicKey(&rsaKey.PublicKey))})
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"sub": "alice", "iss": "https://issuer.example", "aud": "songs-api", "exp": exp, "scope": ScopeRead}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		scopes []string
+	}{
+		{"HS256", sign(t, jwt.SigningMethodHS256, "hs", hsSecret, claims(nil)), []string{ScopeRead}},
+		{"RS256", sign(t, jwt.SigningMethodRS256, "rs", rsaKey, claims(nil)), []string{ScopeRead}},
+		{"scope and scp", sign(t, jwt.SigningMethodRS256, "rs", rsaKey, claims(jwt.MapClaims{"scope": "songs:read songs:write", "scp": []string{"extra"}})),
+			[]string{ScopeRead, ScopeWrite, "extra"}},
+		{"expired within leeway", sign(t, jwt.SigningMethodHS256, "hs", hsSecret, claims(jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()})), []string{ScopeRead}},
+
+		{"HS256 signed with RSA public key", sign(t, jwt.SigningMethodHS256, "rs", publicPEM, claims(nil)), nil},
+		{"RS256 with HS256 kid", sign(t, jwt.SigningMethodRS256, "hs", rsaKey, claims(nil)), nil},
+		{"unknown kid", sign(t, jwt.SigningMethodHS256, "other", hsSecret, claims(nil)), nil},
+		{"no kid with several keys", sign(t, jwt.SigningMethodHS256, "", hsSecret, claims(nil)), nil},
+		{"encryption key is ignored", sign(t, jwt.SigningMethodRS256, "enc", rsaKey, claims(nil)), nil},
+		{"alg none", sign(t, jwt.SigningMethodNone, "hs", jwt.UnsafeAllowNoneSignatureType, claims(nil)), nil},
+		{"HS384", sign(t, jwt.SigningMethodHS384, "hs", hsSecret, claims(nil)), nil},
+		{"wrong secret", sign(t, jwt.SigningMethodHS256, "hs", []byte("fedcba9876543210fedcba9876543210"), claims(nil)), nil},
+		{"expired", sign(t, jwt.SigningMethodHS256, "hs", hsSecret, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})), nil},
+		{"missing exp", sign(t, jwt.SigningMethodHS256, "hs", hsSecret, claims(jwt.MapClaims{"exp": nil})), nil},
+		{"wrong issuer", sign(t, jwt.SigningMethodHS256, "hs", hsSecret, claims(jwt.MapClaims{"iss": "https://evil.example"})), nil},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, "hs", hsSecret, claims(jwt.MapClaims{"aud": "other-api"})), nil},
+		{"malformed", "not.a.jwt", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tt.token)
+			if tt.scopes == nil {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("Verify: err = %v, want ErrInvalidCredentials", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if principal.Subject != "alice" || principal.Method != MethodJWT || !slices.Equal(principal.Scopes, tt.scopes) {
+				t.Errorf("principal = %+v, want alice with scopes %v", principal, tt.scopes)
+			}
+		})
+	}
+}
+
+func TestLoadJWKS(t *testing.T) {
+	tests := []struct {
+		name string
+		jwks string
+	}{
+		{"short HS256 secret", `{"keys":[{"kty":"oct","k":"c2hvcnQ"}]}`},
+		{"unsupported alg", `{"keys":[{"kty":"oct","alg":"HS512","k":"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"}]}`},
+		{"unsupported kty", `{"keys":[{"kty":"EC"}]}`},
+		{"duplicate kid", `{"keys":[{"kty":"oct","kid":"a","k":"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},{"kty":"oct","kid":"a","k":"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"}]}`},
+		{"no signing keys", `{"keys":[]}`},
+		{"malformed", `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "jwks.json")
+			if err := os.WriteFile(path, []byte(tt.jwks), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadJWKS(path, "", ""); err == nil {
+				t.Error("LoadJWKS succeeded, want error")
+			}
+		})
+	}
+}
+
+func rsaTestKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, 2048)
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
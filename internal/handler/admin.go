@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler обслуживает управление пользователями и ролями
+type AdminHandler struct {
+	users    service.UserServiceInterface
+	language string
+}
+
+func NewAdminHandler(users service.UserServiceInterface, language string) *AdminHandler {
+	if !i18n.IsSupported(language) {
+		language = i18n.DefaultLanguage
+	}
+	return &AdminHandler{users: users, language: language}
+}
+
+func (h *AdminHandler) translate(c echo.Context, key string, args ...interface{}) string {
+	return i18n.Translate(negotiateLanguage(c, h.language), key, args...)
+}
+
+// ListUsersHandler возвращает всех пользователей
+// @Summary Список пользователей
+// @Description Возвращает пользователей с их ролями. Доступно только администраторам
+// @Tags admin
+// @Produce json
+// @Success 200 {array} model.User
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Требуется роль admin"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsersHandler(c echo.Context) error {
+	defer startSpan(c, "ListUsersHandler").End()
+	users, err := h.users.ListUsers(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+// CreateUserHandler добавляет пользователя
+// @Summary Добавить пользователя
+// @Description Добавляет пользователя с ролью viewer, editor или admin. Имя сопоставляется с полем sub в JWT
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user body model.UserRequest true "Имя и роль"
+// @Success 200 {object} model.Response "Пользователь создан"
+// @Failure 400 {object} model.Problem "Неверное имя или роль"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Требуется роль admin"
+// @Failure 409 {object} model.Problem "Пользователь с таким именем уже есть"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users [post]
+func (h *AdminHandler) CreateUserHandler(c echo.Context) error {
+	defer startSpan(c, "CreateUserHandler").End()
+	var req model.UserRequest
+	if err := c.Bind(&req); err != nil {
+		return service.NewValidationError(service.CodeMalformedBody, i18n.MalformedBody, err.Error())
+	}
+	user, err := h.users.CreateUser(c.Request().Context(), model.User{Name: req.Name, Role: req.Role})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		Status:  "Success",
+		Message: h.translate(c, i18n.UserCreated),
+		Data:    user,
+	})
+}
+
+// GetUserHandler возвращает пользователя по ID
+// @Summary Получить пользователя
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} model.Response "Пользователь найден"
+// @Failure 400 {object} model.Problem "Неверный формат ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Требуется роль admin"
+// @Failure 404 {object} model.Problem "Пользователь не найден"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUserHandler(c echo.Context) error {
+	defer startSpan(c, "GetUserHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
+	}
+	user, err := h.users.GetUser(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		Status:  "Success",
+		Message: h.translate(c, i18n.UserFound),
+		Data:    user,
+	})
+}
+
+// AssignRoleHandler меняет роль пользователя
+// @Summary Назначить роль
+// @Description Меняет роль пользователя. Новая роль действует со следующего запроса
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param role body model.RoleAssignment true "Новая роль"
+// @Success 200 {object} model.Response "Роль назначена"
+// @Failure 400 {object} model.Problem "Неверный ID или роль"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Требуется роль admin"
+// @Failure 404 {object} model.Problem "Пользователь не найден"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) AssignRoleHandler(c echo.Context) error {
+	defer startSpan(c, "AssignRoleHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
+	}
+	var req model.RoleAssignment
+	if err := c.Bind(&req); err != nil {
+		return service.NewValidationError(service.CodeMalformedBody, i18n.MalformedBody, err.Error())
+	}
+	user, err := h.users.AssignRole(c.Request().Context(), id, req.Role)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		Status:  "Success",
+		Message: h.translate(c, i18n.RoleAssigned),
+		Data:    user,
+	})
+}
+
+// DeleteUserHandler удаляет пользователя
+// @Summary Удалить пользователя
+// @Description Удаляет пользователя и отзывает его ключи API. Его песни остаются без владельца
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} model.Response "Пользователь удалён"
+// @Failure 400 {object} model.Problem "Неверный формат ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Требуется роль admin"
+// @Failure 404 {object} model.Problem "Пользователь не найден"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users/{id} [delete]
+func (h *AdminHandler) DeleteUserHandler(c echo.Context) error {
+	defer startSpan(c, "DeleteUserHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.users.DeleteUser(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		Status:  "Success",
+		Message: h.translate(c, i18n.UserDeleted),
+	})
+}
+
+// ListRolesHandler возвращает доступные роли
+// @Summary Список ролей
+// @Tags admin
+// @Produce json
+// @Success 200 {array} model.Role
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Требуется роль admin"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/roles [get]
+func (h *AdminHandler) ListRolesHandler(c echo.Context) error {
+	defer startSpan(c, "ListRolesHandler").End()
+	roles, err := h.users.ListRoles(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, roles)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newAdminServer собирает маршруты /admin поверх хранилища пользователей в
+// памяти. Вызывающий подставляется без проверки ключа: роль берётся из
+// заголовка X-Test-Role.
+func newAdminServer(t *testing.T) *echo.Echo {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+	users := service.NewUserService(repository.NewMemoryUserRepository(), service.Timeouts{}, nil, logger)
+	songs := NewHandler(nil, i18n.English, logger)
+	h := NewAdminHandler(users, i18n.English)
+
+	e := echo.New()
+	e.HTTPErrorHandler = songs.HTTPErrorHandler
+	admin := e.Group("/admin", func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p := auth.Principal{Subject: "test", Method: auth.MethodAPIKey, Role: c.Request().Header.Get("X-Test-Role")}
+			c.SetRequest(c.Request().WithContext(auth.WithPrincipal(c.Request().Context(), p)))
+			return next(c)
+		}
+	})
+	admin.GET("/users", h.ListUsersHandler)
+	admin.POST("/users", h.CreateUserHandler)
+	admin.GET("/users/:id", h.GetUserHandler)
+	admin.PUT("/users/:id/role", h.AssignRoleHandler)
+	admin.DELETE("/users/:id", h.DeleteUserHandler)
+	admin.GET("/roles", h.ListRolesHandler)
+	return e
+}
+
+func userData(t *testing.T, body []byte) model.User {
+	t.Helper()
+	var resp struct {
+		Data model.User `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode response %q: %v", body, err)
+	}
+	return resp.Data
+}
+
+func TestAdminUsers(t *testing.T) {
+	e := newAdminServer(t)
+	asAdmin := []string{"X-Test-Role", auth.RoleAdmin}
+
+	rec := serve(e, http.MethodPost, "/admin/users", `{"name":" alice ","role":"editor"}`, asAdmin...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	alice := userData(t, rec.Body.Bytes())
+	if alice.ID == 0 || alice.Name != "alice" || alice.Role != auth.RoleEditor {
+		t.Errorf("created user = %+v", alice)
+	}
+
+	expectProblem(t, serve(e, http.MethodPost, "/admin/users", `{"name":"alice","role":"viewer"}`, asAdmin...),
+		http.StatusConflict, service.CodeConflict)
+	problem := expectProblem(t, serve(e, http.MethodPost, "/admin/users", `{"name":"","role":"owner"}`, asAdmin...),
+		http.StatusBadRequest, service.CodeValidationFailed)
+	if len(problem.Errors) != 2 {
+		t.Errorf("field errors = %+v, want name and role", problem.Errors)
+	}
+
+	rec = serve(e, http.MethodPut, "/admin/users/1/role", `{"role":"admin"}`, asAdmin...)
+	if got := userData(t, rec.Body.Bytes()); rec.Code != http.StatusOK || got.Role != auth.RoleAdmin {
+		t.Errorf("assign role: status %d, user %+v", rec.Code, got)
+	}
+	expectProblem(t, serve(e, http.MethodPut, "/admin/users/1/role", `{"role":"root"}`, asAdmin...),
+		http.StatusBadRequest, service.CodeValidationFailed)
+
+	rec = serve(e, http.MethodGet, "/admin/users", "", asAdmin...)
+	users := decode[[]model.User](t, rec)
+	if len(users) != 1 || users[0].Role != auth.RoleAdmin {
+		t.Errorf("users = %+v", users)
+	}
+	roles := decode[[]model.Role](t, serve(e, http.MethodGet, "/admin/roles", "", asAdmin...))
+	if len(roles) != 3 {
+		t.Errorf("roles = %+v", roles)
+	}
+
+	if rec := serve(e, http.MethodDelete, "/admin/users/1", "", asAdmin...); rec.Code != http.StatusOK {
+		t.Errorf("delete: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	expectProblem(t, serve(e, http.MethodGet, "/admin/users/1", "", asAdmin...), http.StatusNotFound, service.CodeUserNotFound)
+	expectProblem(t, serve(e, http.MethodGet, "/admin/users/x", "", asAdmin...), http.StatusBadRequest, service.CodeValidationFailed)
+}
+
+func TestAdminRequiresAdminRole(t *testing.T) {
+	e := newAdminServer(t)
+	for _, role := range []string{auth.RoleViewer, auth.RoleEditor} {
+		t.Run(role, func(t *testing.T) {
+			expectProblem(t, serve(e, http.MethodGet, "/admin/users", "", "X-Test-Role", role), http.StatusForbidden, service.CodeRoleRequired)
+			expectProblem(t, serve(e, http.MethodPost, "/admin/users", `{"name":"bob","role":"admin"}`, "X-Test-Role", role),
+				http.StatusForbidden, service.CodeRoleRequired)
+		})
+	}
+}
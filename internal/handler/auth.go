@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/service"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	headerAPIKey = "X-API-Key"
+	authRealm    = "songs-api"
+)
+
+// Authenticate проверяет ключ API из X-API-Key или токен из
+// Authorization: Bearer и кладёт вызывающего в контекст запроса
+func Authenticate(authenticator *auth.Authenticator, log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := credentials(c.Request())
+			if !ok {
+				return unauthorized(c, "")
+			}
+
+			ctx := c.Request().Context()
+			principal, err := authenticator.Authenticate(ctx, token)
+			switch {
+			case errors.Is(err, auth.ErrInvalidCredentials), errors.Is(err, auth.ErrMethodDisabled):
+				log.InfoContext(ctx, "Authentication failed", "error", err)
+				return unauthorized(c, "invalid_token")
+			case err != nil:
+				return fmt.Errorf("ошибка проверки учётных данных: %w", err)
+			}
+
+			trace.SpanFromContext(ctx).SetAttributes(
+				attribute.String("enduser.id", principal.Subject),
+				attribute.String("auth.method", principal.Method),
+			)
+			c.SetRequest(c.Request().WithContext(auth.WithPrincipal(ctx, principal)))
+			return next(c)
+		}
+	}
+}
+
+// RequireScope пропускает запрос, только если у вызывающего есть право scope.
+// Используется после Authenticate.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := auth.FromContext(c.Request().Context())
+			if !ok {
+				return unauthorized(c, "")
+			}
+			if !principal.HasScope(scope) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate,
+					fmt.Sprintf(`Bearer realm=%q, error="insufficient_scope", scope=%q`, authRealm, scope))
+				return service.NewForbiddenError(scope)
+			}
+			return next(c)
+		}
+	}
+}
+
+// credentials достаёт ключ или токен из запроса. X-API-Key имеет приоритет,
+// ключ API можно передать и как Bearer.
+func credentials(r *http.Request) (string, bool) {
+	if key := strings.TrimSpace(r.Header.Get(headerAPIKey)); key != "" {
+		return key, true
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get(echo.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(c echo.Context, reason string) error {
+	challenge := fmt.Sprintf("Bearer realm=%q", authRealm)
+	if reason != "" {
+		challenge += fmt.Sprintf(", error=%q", reason)
+	}
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+	return service.NewUnauthorizedError()
+}
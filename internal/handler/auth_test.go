@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/service"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newAuthServer собирает маршрут GET /songs, требующий songs:write. Без
+// JWKS и хранилища ключей Authenticate отклоняет любой JWT, поэтому
+// вызывающий со своими правами подставляется заголовком X-Test-Scopes.
+func newAuthServer(t *testing.T) *echo.Echo {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+	h := NewHandler(nil, i18n.English, logger)
+
+	e := echo.New()
+	e.HTTPErrorHandler = h.HTTPErrorHandler
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	e.GET("/songs", ok, Authenticate(auth.NewAuthenticator(nil, nil, nil), logger), RequireScope(auth.ScopeWrite))
+	e.GET("/scoped", ok, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if scopes, set := c.Request().Header[http.CanonicalHeaderKey("X-Test-Scopes")]; set {
+				p := auth.Principal{Subject: "test", Scopes: strings.Fields(scopes[0])}
+				c.SetRequest(c.Request().WithContext(auth.WithPrincipal(c.Request().Context(), p)))
+			}
+			return next(c)
+		}
+	}, RequireScope(auth.ScopeWrite))
+	return e
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	e := newAuthServer(t)
+	tests := []struct {
+		name      string
+		target    string
+		headers   []string
+		status    int
+		code      string
+		challenge string
+	}{
+		{"no credentials", "/songs", nil,
+			http.StatusUnauthorized, service.CodeUnauthorized, `Bearer realm="songs-api"`},
+		{"not a bearer token", "/songs", []string{echo.HeaderAuthorization, "Basic dXNlcjpwYXNz"},
+			http.StatusUnauthorized, service.CodeUnauthorized, `Bearer realm="songs-api"`},
+		{"JWT without JWKS", "/songs", []string{echo.HeaderAuthorization, "Bearer a.b.c"},
+			http.StatusUnauthorized, service.CodeUnauthorized, `Bearer realm="songs-api", error="invalid_token"`},
+		{"no principal", "/scoped", nil,
+			http.StatusUnauthorized, service.CodeUnauthorized, `Bearer realm="songs-api"`},
+		{"missing scope", "/scoped", []string{"X-Test-Scopes", auth.ScopeRead},
+			http.StatusForbidden, service.CodeForbidden, `Bearer realm="songs-api", error="insufficient_scope", scope="songs:write"`},
+		{"no scopes", "/scoped", []string{"X-Test-Scopes", ""},
+			http.StatusForbidden, service.CodeForbidden, `Bearer realm="songs-api", error="insufficient_scope", scope="songs:write"`},
+		{"scope granted", "/scoped", []string{"X-Test-Scopes", auth.ScopeRead + " " + auth.ScopeWrite},
+			http.StatusNoContent, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(e, http.MethodGet, tt.target, "", tt.headers...)
+			if tt.code == "" {
+				if rec.Code != tt.status {
+					t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.status, rec.Body.String())
+				}
+			} else {
+				expectProblem(t, rec, tt.status, tt.code)
+			}
+			if got := rec.Header().Get(echo.HeaderWWWAuthenticate); got != tt.challenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMEProblemJSON   = "application/problem+json"
+	problemTypePrefix = "urn:songs-api:problem:"
+)
+
+// Коды для ошибок, которые возникают вне сервиса
+const (
+	codeInternalError    = "internal_error"
+	codeRouteNotFound    = "route_not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeHTTPError        = "http_error"
+)
+
+// problemFromError сопоставляет ошибку HTTP-статусу и стабильному коду,
+// сообщения ошибок сервиса переводятся на язык lang
+func problemFromError(err error, lang string) model.Problem {
+	var problem model.Problem
+	var domainErr *service.Error
+	var httpErr *echo.HTTPError
+
+	switch {
+	case errors.As(err, &httpErr):
+		problem.Status = httpErr.Code
+		problem.Detail = fmt.Sprint(httpErr.Message)
+		switch httpErr.Code {
+		case http.StatusNotFound:
+			problem.Code = codeRouteNotFound
+		case http.StatusMethodNotAllowed:
+			problem.Code = codeMethodNotAllowed
+		default:
+			problem.Code = codeHTTPError
+		}
+	case errors.As(err, &domainErr):
+		problem.Status = statusFromKind(domainErr.Kind)
+		problem.Detail = i18n.Translate(lang, domainErr.Key, domainErr.Args...)
+		problem.Code = domainErr.Code
+		for _, field := range domainErr.Fields {
+			problem.Errors = append(problem.Errors, model.FieldError{
+				Field:   field.Field,
+				Message: i18n.Translate(lang, field.Key, field.Args...),
+			})
+		}
+	default:
+		// Текст непредвиденной ошибки может содержать SQL и ответы драйвера,
+		// поэтому он остаётся в журнале, а клиент получает общее сообщение
+		problem.Status = http.StatusInternalServerError
+		problem.Detail = i18n.Translate(lang, i18n.InternalError)
+		problem.Code = codeInternalError
+	}
+
+	problem.Type = problemTypePrefix + problem.Code
+	problem.Title = http.StatusText(problem.Status)
+	return problem
+}
+
+func statusFromKind(kind error) int {
+	switch kind {
+	case service.ErrNotFound:
+		return http.StatusNotFound
+	case service.ErrValidation, service.ErrPageOutOfRange:
+		return http.StatusBadRequest
+	case service.ErrConflict:
+		return http.StatusConflict
+	case service.ErrTimeout:
+		return http.StatusGatewayTimeout
+	case service.ErrUnavailable:
+		return http.StatusServiceUnavailable
+	case service.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case service.ErrForbidden:
+		return http.StatusForbidden
+	case service.ErrRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// wantsLegacyError сообщает, что клиент явно запросил application/json
+// и не упомянул application/problem+json. Таким клиентам ошибки отдаются
+// в прежнем формате model.Response.
+func wantsLegacyError(c echo.Context) bool {
+	legacy := false
+	for _, part := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case MIMEProblemJSON:
+			return false
+		case echo.MIMEApplicationJSON:
+			legacy = true
+		}
+	}
+	return legacy
+}
+
+// HTTPErrorHandler — единый обработчик ошибок для echo.Echo.HTTPErrorHandler
+func (h *Handler) HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	problem := problemFromError(err, h.lang(c))
+	problem.Instance = c.Request().URL.RequestURI()
+
+	logger := h.logger
+	if logger == nil {
+		// Используем slog.Default() как fallback, если logger не инициализирован
+		logger = slog.Default()
+	}
+	// Ошибки клиента штатны и уже видны в журнале доступа, поэтому
+	// уровнем Error отмечаются только сбои сервера
+	level := slog.LevelWarn
+	if problem.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logger.Log(c.Request().Context(), level, "Request failed", "method", c.Request().Method, "path", c.Request().URL.Path,
+		"status", problem.Status, "code", problem.Code, "message", err.Error())
+
+	switch {
+	case c.Request().Method == http.MethodHead:
+		err = c.NoContent(problem.Status)
+	case wantsLegacyError(c):
+		err = c.JSON(problem.Status, model.Response{
+			Status:  "Error",
+			Message: problem.Detail,
+		})
+	default:
+		c.Response().Header().Set(echo.HeaderContentType, MIMEProblemJSON)
+		c.Response().WriteHeader(problem.Status)
+		err = json.NewEncoder(c.Response()).Encode(problem)
+	}
+	if err != nil {
+		logger.ErrorContext(c.Request().Context(), "Failed to write error response", "error", err)
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"awesomeProject/internal/health"
+	"awesomeProject/internal/model"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// LivenessHandler godoc
+// @Summary Проверка жизнеспособности
+// @Description Отвечает 200, пока процесс работает; состояние базы данных не проверяется
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.HealthResponse
+// @Router /healthz [get]
+func (h *HealthHandler) LivenessHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, model.HealthResponse{Status: model.HealthStatusOK})
+}
+
+// ReadinessHandler godoc
+// @Summary Проверка готовности
+// @Description Проверяет доступность базы данных и соответствие версии схемы последней миграции.
+// @Description Во время плавной остановки сервиса всегда отвечает 503.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.HealthResponse
+// @Failure 503 {object} model.HealthResponse "Сервис не готов принимать запросы"
+// @Router /readyz [get]
+func (h *HealthHandler) ReadinessHandler(c echo.Context) error {
+	resp, ok := h.checker.Ready(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusServiceUnavailable, resp)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"awesomeProject/internal/health"
+	"awesomeProject/internal/model"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newHealthServer собирает /healthz и /readyz над базой SQLite в памяти, в
+// которой golang-migrate записал бы версию схемы version
+func newHealthServer(t *testing.T, version uint) (*echo.Echo, *health.Checker, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version bigint NOT NULL, dirty boolean NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (?, false)`, version); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := health.NewChecker(db, 3, time.Second, slog.New(slog.DiscardHandler))
+	h := NewHealthHandler(checker)
+	e := echo.New()
+	e.GET("/healthz", h.LivenessHandler)
+	e.GET("/readyz", h.ReadinessHandler)
+	return e, checker, db
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name    string
+		version uint
+		prepare func(checker *health.Checker, db *sql.DB)
+		status  int
+		failed  string
+	}{
+		{"ready", 3, nil, http.StatusOK, ""},
+		{"database unavailable", 3, func(_ *health.Checker, db *sql.DB) { db.Close() }, http.StatusServiceUnavailable, health.CheckDatabase},
+		{"schema version mismatch", 2, nil, http.StatusServiceUnavailable, health.CheckMigrations},
+		{"shutting down", 3, func(checker *health.Checker, _ *sql.DB) { checker.SetShuttingDown() }, http.StatusServiceUnavailable, health.CheckShutdown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, checker, db := newHealthServer(t, tt.version)
+			if tt.prepare != nil {
+				tt.prepare(checker, db)
+			}
+
+			rec := serve(e, http.MethodGet, "/readyz", "")
+			if rec.Code != tt.status {
+				t.Fatalf("readyz status = %d, want %d, body %s", rec.Code, tt.status, rec.Body.String())
+			}
+			resp := decode[model.HealthResponse](t, rec)
+			if tt.failed != "" {
+				check := resp.Checks[tt.failed]
+				if resp.Status != model.HealthStatusFail || check.Status != model.HealthStatusFail || check.Error != "unavailable" {
+					t.Errorf("readyz = %+v, want check %q failed", resp, tt.failed)
+				}
+			}
+
+			// Живость не зависит от готовности
+			if rec := serve(e, http.MethodGet, "/healthz", ""); rec.Code != http.StatusOK {
+				t.Errorf("healthz status = %d, want %d", rec.Code, http.StatusOK)
+			}
+		})
+	}
+}
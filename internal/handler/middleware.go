@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"awesomeProject/internal/logger"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxRequestIDLength ограничивает длину принятого от клиента X-Request-ID
+const maxRequestIDLength = 128
+
+// RequestID берёт ID запроса из заголовка X-Request-ID или генерирует новый,
+// возвращает его в ответе и кладёт в контекст для логов
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(c.Request().WithContext(logger.WithRequestID(c.Request().Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+// validRequestID допускает только печатные ASCII-символы, чтобы значение
+// из заголовка нельзя было использовать для подделки строк лога
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog пишет по одной записи на каждый обработанный запрос. Статус
+// берётся из ответа, поэтому ошибки к этому моменту должен обработать ResolveError.
+func AccessLog(log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			req := c.Request()
+			res := c.Response()
+			level := slog.LevelInfo
+			if res.Status >= 500 {
+				level = slog.LevelError
+			}
+			log.LogAttrs(req.Context(), level, "HTTP request",
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.String("route", c.Path()),
+				slog.Int("status", res.Status),
+				slog.Int64("bytes", res.Size),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_ip", c.RealIP()),
+				slog.String("user_agent", req.UserAgent()),
+			)
+			return err
+		}
+	}
+}
+
+// ResolveError сразу передаёт ошибку обработчика в HTTPErrorHandler, чтобы
+// внешние middleware (трассировка, журнал запросов, метрики) видели итоговый
+// статус ответа в c.Response(). Регистрируется последним из общих middleware,
+// внешние только пропускают возвращённую ошибку дальше.
+func ResolveError() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAccessLogSeesResolvedStatus(t *testing.T) {
+	var buf bytes.Buffer
+	e := echo.New()
+	errorHandlerCalls := 0
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		errorHandlerCalls++
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+	e.Use(AccessLog(slog.New(slog.NewTextHandler(&buf, nil))))
+	e.Use(ResolveError())
+	e.GET("/teapot", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/teapot", nil))
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+	if errorHandlerCalls != 1 {
+		t.Errorf("HTTPErrorHandler called %d times, want 1", errorHandlerCalls)
+	}
+	if !strings.Contains(buf.String(), "status=418") {
+		t.Errorf("access log = %q, want status=418", buf.String())
+	}
+}
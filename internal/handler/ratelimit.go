@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/ratelimit"
+	"awesomeProject/internal/service"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Группы маршрутов с отдельными лимитами
+const (
+	RateLimitSearch = "search"
+	RateLimitRead   = "read"
+	RateLimitWrite  = "write"
+	RateLimitAdmin  = "admin"
+	// RateLimitAuth — лимит по IP перед Authenticate: проверка ключа API
+	// обращается к базе, и перебор ключей не должен обходить ограничения
+	RateLimitAuth = "auth"
+)
+
+// RateLimit ограничивает частоту запросов группы маршрутов. Клиент
+// определяется по ключу API или субъекту токена, а без аутентификации — по IP:
+// после Authenticate лимит действует на вызывающего, до него — на адрес. Ответ дополняется
+// заголовками RateLimit-* (draft-ietf-httpapi-ratelimit-headers), при
+// превышении возвращается 429 с Retry-After. Если хранилище недоступно,
+// запрос пропускается: лимит защищает базу, но не должен её подменять.
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit, log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !limit.Enabled() {
+			return next
+		}
+		policy := fmt.Sprintf("%d;w=60;burst=%d", limit.PerMinute, max(limit.Burst, 1))
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			result, err := store.Take(ctx, group+"|"+clientKey(c), limit)
+			if err != nil {
+				log.ErrorContext(ctx, "Rate limit store failed, request allowed", "group", group, "error", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Policy", policy)
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			if !result.Allowed {
+				retryAfter := ceilSeconds(result.RetryAfter)
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+				log.InfoContext(ctx, "Rate limit exceeded", "group", group, "client", clientKey(c))
+				return service.NewRateLimitError(retryAfter)
+			}
+			return next(c)
+		}
+	}
+}
+
+// clientKey возвращает идентификатор клиента для корзины
+func clientKey(c echo.Context) string {
+	if principal, ok := auth.FromContext(c.Request().Context()); ok {
+		return principal.Method + ":" + principal.Subject
+	}
+	return "ip:" + c.RealIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
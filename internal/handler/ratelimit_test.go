@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/ratelimit"
+	"awesomeProject/internal/service"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// recordingStore запоминает ключи корзин и отвечает заданным результатом
+type recordingStore struct {
+	keys   []string
+	result ratelimit.Result
+	err    error
+}
+
+func (s *recordingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	return s.result, s.err
+}
+
+// newRateLimitServer собирает маршрут GET /songs с лимитом группы read.
+// Вызывающий подставляется заголовком X-Test-Subject, IP берётся из X-Real-IP.
+func newRateLimitServer(t *testing.T, store ratelimit.Store) *echo.Echo {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+	h := NewHandler(nil, i18n.English, logger)
+
+	e := echo.New()
+	e.HTTPErrorHandler = h.HTTPErrorHandler
+	e.IPExtractor = func(r *http.Request) string { return r.Header.Get(echo.HeaderXRealIP) }
+	principal := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if subject := c.Request().Header.Get("X-Test-Subject"); subject != "" {
+				p := auth.Principal{Subject: subject, Method: auth.MethodJWT}
+				c.SetRequest(c.Request().WithContext(auth.WithPrincipal(c.Request().Context(), p)))
+			}
+			return next(c)
+		}
+	}
+	e.GET("/songs", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) },
+		principal, RateLimit(store, RateLimitRead, ratelimit.Limit{PerMinute: 60, Burst: 5}, logger))
+	return e
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("allowed", func(t *testing.T) {
+		store := &recordingStore{result: ratelimit.Result{Allowed: true, Limit: 5, Remaining: 4, Reset: 1500 * time.Millisecond}}
+		rec := serve(newRateLimitServer(t, store), http.MethodGet, "/songs", "")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		want := map[string]string{
+			"RateLimit-Policy":    "60;w=60;burst=5",
+			"RateLimit-Limit":     "5",
+			"RateLimit-Remaining": "4",
+			"RateLimit-Reset":     "2",
+			echo.HeaderRetryAfter: "",
+		}
+		for name, value := range want {
+			if got := rec.Header().Get(name); got != value {
+				t.Errorf("%s = %q, want %q", name, got, value)
+			}
+		}
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		store := &recordingStore{result: ratelimit.Result{Limit: 5, Reset: 5 * time.Second, RetryAfter: 200 * time.Millisecond}}
+		rec := serve(newRateLimitServer(t, store), http.MethodGet, "/songs", "")
+		expectProblem(t, rec, http.StatusTooManyRequests, service.CodeRateLimited)
+		want := map[string]string{
+			"RateLimit-Limit":     "5",
+			"RateLimit-Remaining": "0",
+			"RateLimit-Reset":     "5",
+			echo.HeaderRetryAfter: "1",
+		}
+		for name, value := range want {
+			if got := rec.Header().Get(name); got != value {
+				t.Errorf("%s = %q, want %q", name, got, value)
+			}
+		}
+	})
+
+	t.Run("store failure lets the request through", func(t *testing.T) {
+		store := &recordingStore{err: errors.New("store is down")}
+		rec := serve(newRateLimitServer(t, store), http.MethodGet, "/songs", "")
+		if rec.Code != http.StatusNoContent || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("status = %d, RateLimit-Limit %q, want %d without headers", rec.Code, rec.Header().Get("RateLimit-Limit"), http.StatusNoContent)
+		}
+	})
+}
+
+func TestRateLimitClientKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{"anonymous by IP", []string{echo.HeaderXRealIP, "203.0.113.7"}, "read|ip:203.0.113.7"},
+		{"principal wins over IP", []string{echo.HeaderXRealIP, "203.0.113.7", "X-Test-Subject", "alice"}, "read|jwt:alice"},
+		{"same principal from another IP", []string{echo.HeaderXRealIP, "198.51.100.1", "X-Test-Subject", "alice"}, "read|jwt:alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStore{result: ratelimit.Result{Allowed: true}}
+			serve(newRateLimitServer(t, store), http.MethodGet, "/songs", "", tt.headers...)
+			if len(store.keys) != 1 || store.keys[0] != tt.want {
+				t.Errorf("keys = %q, want [%q]", store.keys, tt.want)
+			}
+		})
+	}
+}
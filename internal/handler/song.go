@@ -1,93 +1,381 @@
 package handler
 
 import (
+	"awesomeProject/internal/i18n"
 	"awesomeProject/internal/model"
 	"awesomeProject/internal/service"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
+// maxPageSize ограничивает размер страницы списка и поиска, чтобы один запрос
+// не вычитывал всю таблицу или все совпадения
+const maxPageSize = 100
+
+// maxPage ограничивает номер страницы: смещение (page-1)*page_size должно
+// оставаться в пределах int, иначе оно переполняется и становится отрицательным
+const maxPage = 100000
+
+// defaultPageSize — размер страницы списка песен, если page_size не указан
+const defaultPageSize = 10
+
+// maxFilterIDs ограничивает число ID в параметре ids
+const maxFilterIDs = 100
+
 type Handler struct {
 	service service.SongServiceInterface
 	logger  *slog.Logger
+	// language — язык ответов, если Accept-Language не совпал ни с одним поддерживаемым
+	language string
+}
+
+func NewHandler(service service.SongServiceInterface, language string, logger *slog.Logger) *Handler {
+	if !i18n.IsSupported(language) {
+		language = i18n.DefaultLanguage
+	}
+	return &Handler{service: service, language: language, logger: logger}
+}
+
+func (h *Handler) lang(c echo.Context) string {
+	return negotiateLanguage(c, h.language)
 }
 
-func NewHandler(service service.SongServiceInterface, logger *slog.Logger) *Handler {
-	return &Handler{service: service, logger: logger}
+// negotiateLanguage выбирает язык ответа по Accept-Language и сообщает его в Content-Language
+func negotiateLanguage(c echo.Context, fallback string) string {
+	lang := i18n.Negotiate(c.Request().Header.Get("Accept-Language"), fallback)
+	c.Response().Header().Set("Content-Language", lang)
+	return lang
+}
+
+func (h *Handler) translate(c echo.Context, key string, args ...interface{}) string {
+	return i18n.Translate(h.lang(c), key, args...)
 }
 
 func parseID(c echo.Context) (int64, error) {
 	idStr := c.QueryParam("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		return 0, fmt.Errorf("укажите корректный ID песни")
+		return 0, service.NewFieldError("id", i18n.InvalidID)
 	}
 	return id, nil
 }
-func (h *Handler) errorResponse(c echo.Context, status int, message string) error {
-	if h.logger == nil {
-		// Используем slog.Default() как fallback, если logger не инициализирован
-		slog.Default().Error("Request failed, logger is nil", "method", c.Request().Method, "path", c.Request().URL.Path, "status", status, "message", message)
-	} else {
-		h.logger.Error("Request failed", "method", c.Request().Method, "path", c.Request().URL.Path, "status", status, "message", message)
-	}
-	return c.JSON(status, model.Response{
-		Status:  "Error",
-		Message: message,
-	})
+
+func parsePathID(c echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, service.NewFieldError("id", i18n.InvalidID)
+	}
+	return id, nil
+}
+
+// parsePositive читает положительное целое из query-параметра param. Пустое
+// значение заменяется на def; limit > 0 задаёт наибольшее допустимое значение.
+func parsePositive(c echo.Context, param string, def, limit int) (int, error) {
+	v := c.QueryParam(param)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, service.NewFieldError(param, i18n.InvalidParam, param)
+	}
+	if limit > 0 && n > limit {
+		return 0, service.NewFieldError(param, i18n.ParamTooLarge, param, limit)
+	}
+	return n, nil
+}
+
+// parseIDList разбирает список ID через запятую; повторы отбрасываются
+func parseIDList(value string) ([]int64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) > maxFilterIDs {
+		return nil, service.NewFieldError("ids", i18n.TooManyIDs, maxFilterIDs)
+	}
+	ids := make([]int64, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || id <= 0 {
+			return nil, service.NewFieldError("ids", i18n.InvalidIDList, part)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// parseSort разбирает порядок вида group,-song,id: минус перед полем задаёт
+// сортировку по убыванию. Допустимы только поля из model.SortFields.
+func parseSort(value string) ([]model.SortKey, error) {
+	var keys []model.SortKey
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		field, desc := strings.CutPrefix(part, "-")
+		if !slices.Contains(model.SortFields, field) {
+			return nil, service.NewFieldError("sort", i18n.InvalidSortField, part, strings.Join(model.SortFields, ", "))
+		}
+		for _, key := range keys {
+			if key.Field == field {
+				return nil, service.NewFieldError("sort", i18n.DuplicateSortField, field)
+			}
+		}
+		keys = append(keys, model.SortKey{Field: field, Desc: desc})
+	}
+	return keys, nil
+}
+
+// includableFields — поля, которые не входят в список по умолчанию и
+// добавляются параметром include
+var includableFields = []string{model.FieldText}
+
+// parseListFields определяет поля песен в списке: перечисленные в fields
+// или model.DefaultListFields, плюс поля из include
+func parseListFields(fieldsParam, includeParam string) ([]string, error) {
+	fields := model.DefaultListFields
+	if fieldsParam != "" {
+		var err error
+		if fields, err = parseFieldList("fields", fieldsParam, model.SongFields); err != nil {
+			return nil, err
+		}
+	}
+	if includeParam != "" {
+		include, err := parseFieldList("include", includeParam, includableFields)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(slices.Clip(fields), include...)
+	}
+	return fields, nil
+}
+
+// parseFieldList разбирает список полей через запятую; повторы отбрасываются
+func parseFieldList(param, value string, allowed []string) ([]string, error) {
+	var fields []string
+	for _, part := range strings.Split(value, ",") {
+		field := strings.TrimSpace(part)
+		if !slices.Contains(allowed, field) {
+			return nil, service.NewFieldError(param, i18n.InvalidFieldName, field, param, strings.Join(allowed, ", "))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// setDeprecation помечает ответ устаревшего маршрута и указывает на его замену
+func setDeprecation(c echo.Context, id int64) {
+	c.Response().Header().Set("Deprecation", "true")
+	c.Response().Header().Set("Link", fmt.Sprintf("</songs/%d>; rel=\"successor-version\"", id))
+}
+
+// validateSong проверяет обязательные поля и приводит дату выхода к единому формату
+func validateSong(song *model.Song) error {
+	var fields []service.FieldViolation
+	if song.Group == "" {
+		fields = append(fields, service.FieldViolation{Field: "group", Key: i18n.GroupRequired})
+	}
+	if song.Song == "" {
+		fields = append(fields, service.FieldViolation{Field: "song", Key: i18n.SongRequired})
+	}
+	if song.ReleaseDate != "" {
+		date, err := model.NormalizeDate(song.ReleaseDate)
+		if err != nil {
+			fields = append(fields, service.FieldViolation{Field: "release_date", Key: i18n.InvalidDate, Args: []interface{}{song.ReleaseDate}})
+		}
+		song.ReleaseDate = date
+	}
+	if len(fields) > 0 {
+		return service.NewFieldErrors(i18n.InvalidSong, fields...)
+	}
+	return nil
+}
+
+// bindSongPatch разбирает тело JSON Merge Patch. echo.Bind не подходит:
+// он не понимает application/merge-patch+json и не отличает null от отсутствия поля.
+func bindSongPatch(c echo.Context) (model.SongPatch, error) {
+	var patch model.SongPatch
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return patch, service.NewValidationError(service.CodeMalformedBody, i18n.MalformedBody, err.Error())
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return patch, service.NewValidationError(service.CodeMalformedBody, i18n.BodyNotObject)
+	}
+	if err := json.Unmarshal(trimmed, &patch); err != nil {
+		return patch, service.NewValidationError(service.CodeMalformedBody, i18n.MalformedBody, err.Error())
+	}
+
+	var fields []service.FieldViolation
+	if patch.Group.Set && (patch.Group.Null || patch.Group.Value == "") {
+		fields = append(fields, service.FieldViolation{Field: "group", Key: i18n.GroupRequired})
+	}
+	if patch.Song.Set && (patch.Song.Null || patch.Song.Value == "") {
+		fields = append(fields, service.FieldViolation{Field: "song", Key: i18n.SongRequired})
+	}
+	if patch.ReleaseDate.Set && !patch.ReleaseDate.Null {
+		date, err := model.NormalizeDate(patch.ReleaseDate.Value)
+		if err != nil {
+			fields = append(fields, service.FieldViolation{Field: "release_date", Key: i18n.InvalidDate, Args: []interface{}{patch.ReleaseDate.Value}})
+		}
+		patch.ReleaseDate.Value = date
+	}
+	if len(fields) > 0 {
+		return patch, service.NewFieldErrors(i18n.InvalidSong, fields...)
+	}
+	return patch, nil
 }
 
 // GetHandler возвращает список песен с фильтрацией и пагинацией
 // @Summary Получить список песен
-// @Description Возвращает список песен с возможностью фильтрации по ID, группе, названию и пагинацией
+// @Description Возвращает список песен с фильтрацией по ID, группе, названию, тексту и дате выхода и сортировкой по нескольким полям.
+// @Description Страницы читаются по курсорам (next_cursor, prev_cursor) или по номеру page.
+// @Description Текст песен в список не входит, его добавляет include=text; набор полей ограничивает fields
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param id query int false "ID песни"
+// @Param ids query string false "Список ID через запятую, не больше 100; несовместим с id" example(1,2,3)
 // @Param group query string false "Название группы"
 // @Param song query string false "Название песни"
-// @Param page query int false "Номер страницы" default(1)
-// @Param page_size query int false "Размер страницы" default(10)
+// @Param match query string false "Сравнение группы и названия: подстрока, начало или точное совпадение (без учёта регистра)" Enums(contains, prefix, exact) default(contains)
+// @Param lyrics query string false "Подстрока текста песни"
+// @Param has_text query bool false "Только песни с текстом (true) или без него (false)"
+// @Param release_from query string false "Дата выхода не раньше (ГГГГ-ММ-ДД)"
+// @Param release_to query string false "Дата выхода не позже (ГГГГ-ММ-ДД)"
+// @Param fuzzy query bool false "Нечёткое сравнение группы и названия с учётом опечаток; только с match=contains" default(false)
+// @Param sort query string false "Поля сортировки через запятую (id, group, song, release_date), минус — по убыванию. По умолчанию по id, в нечётком режиме по сходству" example(group,-release_date)
+// @Param page query int false "Номер страницы (режим со смещением); без него и без cursor возвращается первая страница с next_cursor; не больше 100000"
+// @Param cursor query string false "Курсор next_cursor или prev_cursor из предыдущего ответа; несовместим с page"
+// @Param page_size query int false "Размер страницы (не больше 100)" default(10)
+// @Param include_total query bool false "Подсчитывать total и totalPages (отдельный запрос COUNT)" default(true)
+// @Param fields query string false "Возвращаемые поля через запятую (id, group, song, text, release_date, link, created_by); ID возвращается всегда. По умолчанию все, кроме text" example(id,group,song)
+// @Param include query string false "Добавить к полям списка текст песни" Enums(text)
 // @Success 200 {object} model.SongsResponse
-// @Failure 400 {object} model.Response "Неверные параметры запроса"
-// @Failure 500 {object} model.Response "Внутренняя ошибка сервера"
+// @Failure 400 {object} model.Problem "Неверные параметры запроса"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [get]
 func (h *Handler) GetHandler(c echo.Context) error {
-	filterGroup := strings.ToLower(c.QueryParam("group"))
-	filterSong := strings.ToLower(c.QueryParam("song"))
+	defer startSpan(c, "GetHandler").End()
+	filter := model.SongFilter{
+		Group:  strings.ToLower(c.QueryParam("group")),
+		Song:   strings.ToLower(c.QueryParam("song")),
+		Lyrics: strings.ToLower(c.QueryParam("lyrics")),
+	}
 	filterIDStr := c.QueryParam("id")
-	var filterID int64
-	var filterByID bool
 
 	if filterIDStr != "" {
 		id, err := strconv.ParseInt(filterIDStr, 10, 64)
 		if err != nil {
-			return h.errorResponse(c, http.StatusBadRequest, "Неверный формат ID")
+			return service.NewFieldError("id", i18n.InvalidID)
 		}
-		filterID = id
-		filterByID = true
+		filter.ID = id
+		filter.ByID = true
+	}
+	if v := c.QueryParam("ids"); v != "" {
+		if filter.ByID {
+			return service.NewFieldError("ids", i18n.IDWithIDs)
+		}
+		ids, err := parseIDList(v)
+		if err != nil {
+			return err
+		}
+		filter.IDs = ids
+	}
+	if v := c.QueryParam("match"); v != "" {
+		switch mode := model.MatchMode(v); mode {
+		case model.MatchContains, model.MatchPrefix, model.MatchExact:
+			filter.Match = mode
+		default:
+			return service.NewFieldError("match", i18n.InvalidParam, "match")
+		}
+	}
+	if v := c.QueryParam("release_from"); v != "" {
+		date, err := model.NormalizeDate(v)
+		if err != nil {
+			return service.NewFieldError("release_from", i18n.InvalidDate, v)
+		}
+		filter.ReleaseFrom = date
+	}
+	if v := c.QueryParam("release_to"); v != "" {
+		date, err := model.NormalizeDate(v)
+		if err != nil {
+			return service.NewFieldError("release_to", i18n.InvalidDate, v)
+		}
+		filter.ReleaseTo = date
+	}
+	if v := c.QueryParam("fuzzy"); v != "" {
+		fuzzy, err := strconv.ParseBool(v)
+		if err != nil {
+			return service.NewFieldError("fuzzy", i18n.InvalidParam, "fuzzy")
+		}
+		filter.Fuzzy = fuzzy
+	}
+	if filter.Fuzzy && filter.Match != "" && filter.Match != model.MatchContains {
+		return service.NewFieldError("fuzzy", i18n.FuzzyWithMatch)
+	}
+	if v := c.QueryParam("has_text"); v != "" {
+		hasText, err := strconv.ParseBool(v)
+		if err != nil {
+			return service.NewFieldError("has_text", i18n.InvalidParam, "has_text")
+		}
+		filter.HasText = &hasText
+	}
+	if filter.ReleaseFrom != "" && filter.ReleaseTo != "" && filter.ReleaseFrom > filter.ReleaseTo {
+		return service.NewFieldError("release_from", i18n.InvalidReleaseRange)
+	}
+	if v := c.QueryParam("sort"); v != "" {
+		keys, err := parseSort(v)
+		if err != nil {
+			return err
+		}
+		filter.Sort = keys
 	}
 
-	page, err := strconv.Atoi(c.QueryParam("page"))
-	if err != nil || page < 1 {
-		page = 1
+	page := model.PageRequest{Cursor: c.QueryParam("cursor"), IncludeTotal: true}
+	if c.QueryParam("page") != "" && page.Cursor != "" {
+		return service.NewFieldError("cursor", i18n.CursorWithPage)
+	}
+	var err error
+	if page.Page, err = parsePositive(c, "page", 0, maxPage); err != nil {
+		return err
+	}
+	if page.Size, err = parsePositive(c, "page_size", defaultPageSize, maxPageSize); err != nil {
+		return err
+	}
+	if v := c.QueryParam("include_total"); v != "" {
+		includeTotal, err := strconv.ParseBool(v)
+		if err != nil {
+			return service.NewFieldError("include_total", i18n.InvalidParam, "include_total")
+		}
+		page.IncludeTotal = includeTotal
 	}
-	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
-	if err != nil || pageSize < 1 {
-		pageSize = 10
+
+	fields, err := parseListFields(c.QueryParam("fields"), c.QueryParam("include"))
+	if err != nil {
+		return err
 	}
 
-	resp, err := h.service.GetSongs(filterID, filterByID, filterGroup, filterSong, page, pageSize)
+	resp, err := h.service.GetSongs(c.Request().Context(), filter, fields, page)
 	if err != nil {
-		if strings.Contains(err.Error(), "запрошенная страница") {
-			return h.errorResponse(c, http.StatusBadRequest, err.Error())
-		}
-		return h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, resp)
@@ -95,181 +383,431 @@ func (h *Handler) GetHandler(c echo.Context) error {
 
 // PostHandler добавляет новую песню
 // @Summary Добавить новую песню
-// @Description Добавляет новую песню с указанными данными
+// @Description Добавляет новую песню. Если текст не передан, он запрашивается во внешнем сервисе информации о песнях
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param song body model.Song true "Данные песни"
 // @Success 200 {object} model.Response "Песня успешно добавлена"
-// @Failure 400 {object} model.Response "Неверный формат данных или пустые поля"
-// @Failure 500 {object} model.Response "Внутренняя ошибка сервера"
+// @Failure 400 {object} model.Problem "Неверный формат данных или пустые поля"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [post]
 func (h *Handler) PostHandler(c echo.Context) error {
+	defer startSpan(c, "PostHandler").End()
 	var song model.Song
 	if err := c.Bind(&song); err != nil {
-		return h.errorResponse(c, http.StatusBadRequest, "Не смогли добавить песню: "+err.Error())
+		return service.NewValidationError(service.CodeMalformedBody, i18n.MalformedBody, err.Error())
 	}
-
-	if song.Group == "" || song.Song == "" {
-		return h.errorResponse(c, http.StatusBadRequest, "Группа или название песни не могут быть пустыми")
+	if err := validateSong(&song); err != nil {
+		return err
 	}
 
-	newSong, err := h.service.AddSong(song)
+	newSong, err := h.service.AddSong(c.Request().Context(), song)
 	if err != nil {
-		return h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, model.Response{
 		Status:  "Success",
-		Message: "Песня добавлена",
+		Message: h.translate(c, i18n.SongAdded),
 		Data:    newSong,
 	})
 }
 
+// GetSongHandler возвращает одну песню по ID
+// @Summary Получить песню
+// @Description Возвращает песню по указанному ID
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} model.Response "Песня найдена"
+// @Failure 400 {object} model.Problem "Неверный формат ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав"
+// @Failure 404 {object} model.Problem "Песня не найдена"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id} [get]
+func (h *Handler) GetSongHandler(c echo.Context) error {
+	defer startSpan(c, "GetSongHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
+	}
+
+	song, err := h.service.GetSong(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.Response{
+		Status:  "Success",
+		Message: h.translate(c, i18n.SongFound),
+		Data:    song,
+	})
+}
+
+// PutHandler полностью заменяет данные песни
+// @Summary Заменить песню
+// @Description Полностью заменяет данные песни по указанному ID. Не переданные текст, дата выхода и ссылка очищаются
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param song body model.Song true "Новые данные песни"
+// @Success 200 {object} model.Response "Песня успешно заменена"
+// @Failure 400 {object} model.Problem "Неверный формат данных или ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав или песня добавлена другим пользователем"
+// @Failure 404 {object} model.Problem "Песня не найдена"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id} [put]
+func (h *Handler) PutHandler(c echo.Context) error {
+	defer startSpan(c, "PutHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
+	}
+
+	var song model.Song
+	if err := c.Bind(&song); err != nil {
+		return service.NewValidationError(service.CodeMalformedBody, i18n.MalformedBody, err.Error())
+	}
+	if err := validateSong(&song); err != nil {
+		return err
+	}
+
+	replaced, err := h.service.ReplaceSong(c.Request().Context(), id, song)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.Response{
+		Status:  "Success",
+		Message: h.translate(c, i18n.SongReplaced),
+		Data:    replaced,
+	})
+}
+
 // DeleteHandler удаляет песню по ID
 // @Summary Удалить песню
 // @Description Удаляет песню по указанному ID
 // @Tags songs
 // @Accept json
 // @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} model.Response "Песня успешно удалена"
+// @Failure 400 {object} model.Problem "Неверный формат ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав или песня добавлена другим пользователем"
+// @Failure 404 {object} model.Problem "Песня не найдена"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id} [delete]
+func (h *Handler) DeleteHandler(c echo.Context) error {
+	defer startSpan(c, "DeleteHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
+	}
+	return h.deleteSong(c, id)
+}
+
+// LegacyDeleteHandler удаляет песню по ID из query-параметра
+// @Summary Удалить песню (устарело)
+// @Description Устаревший вариант DELETE /songs/{id}. Ответ содержит заголовок Deprecation
+// @Tags songs
+// @Accept json
+// @Produce json
 // @Param id query int true "ID песни"
 // @Success 200 {object} model.Response "Песня успешно удалена"
-// @Failure 400 {object} model.Response "Неверный формат ID или песня не найдена"
-// @Failure 500 {object} model.Response "Внутренняя ошибка сервера"
+// @Failure 400 {object} model.Problem "Неверный формат ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав или песня добавлена другим пользователем"
+// @Failure 404 {object} model.Problem "Песня не найдена"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Deprecated
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [delete]
-func (h *Handler) DeleteHandler(c echo.Context) error {
+func (h *Handler) LegacyDeleteHandler(c echo.Context) error {
+	defer startSpan(c, "LegacyDeleteHandler").End()
 	id, err := parseID(c)
 	if err != nil {
-		return h.errorResponse(c, http.StatusBadRequest, err.Error())
+		return err
 	}
+	setDeprecation(c, id)
+	return h.deleteSong(c, id)
+}
 
-	if err := h.service.DeleteSong(id); err != nil {
-		if strings.Contains(err.Error(), "не найдена") {
-			return h.errorResponse(c, http.StatusBadRequest, err.Error())
-		}
-		return h.errorResponse(c, http.StatusInternalServerError, err.Error())
+func (h *Handler) deleteSong(c echo.Context, id int64) error {
+	if err := h.service.DeleteSong(c.Request().Context(), id); err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, model.Response{
 		Status:  "Success",
-		Message: "Песня удалена",
+		Message: h.translate(c, i18n.SongDeleted),
 	})
 }
 
 // PatchHandler обновляет данные песни
 // @Summary Обновить песню
-// @Description Обновляет данные песни по указанному ID
+// @Description Обновляет данные песни по указанному ID по правилам JSON Merge Patch (RFC 7386):
+// @Description отсутствующие поля не меняются, null очищает поле. Группу и название очистить нельзя
 // @Tags songs
-// @Accept json
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param song body model.SongPatch true "Обновляемые данные песни"
+// @Success 200 {object} model.Response "Песня успешно обновлена"
+// @Failure 400 {object} model.Problem "Неверный формат данных или ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав или песня добавлена другим пользователем"
+// @Failure 404 {object} model.Problem "Песня не найдена"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id} [patch]
+func (h *Handler) PatchHandler(c echo.Context) error {
+	defer startSpan(c, "PatchHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
+	}
+	return h.patchSong(c, id)
+}
+
+// LegacyPatchHandler обновляет данные песни по ID из query-параметра
+// @Summary Обновить песню (устарело)
+// @Description Устаревший вариант PATCH /songs/{id}. Ответ содержит заголовок Deprecation
+// @Tags songs
+// @Accept json,application/merge-patch+json
 // @Produce json
 // @Param id query int true "ID песни"
-// @Param song body model.Song true "Обновляемые данные песни"
+// @Param song body model.SongPatch true "Обновляемые данные песни"
 // @Success 200 {object} model.Response "Песня успешно обновлена"
-// @Failure 400 {object} model.Response "Неверный формат данных или ID"
-// @Failure 500 {object} model.Response "Внутренняя ошибка сервера"
+// @Failure 400 {object} model.Problem "Неверный формат данных или ID"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав или песня добавлена другим пользователем"
+// @Failure 404 {object} model.Problem "Песня не найдена"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Deprecated
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [patch]
-func (h *Handler) PatchHandler(c echo.Context) error {
+func (h *Handler) LegacyPatchHandler(c echo.Context) error {
+	defer startSpan(c, "LegacyPatchHandler").End()
 	id, err := parseID(c)
 	if err != nil {
-		return h.errorResponse(c, http.StatusBadRequest, err.Error())
+		return err
 	}
+	setDeprecation(c, id)
+	return h.patchSong(c, id)
+}
 
-	var updateSong model.Song
-	if err := c.Bind(&updateSong); err != nil {
-		return h.errorResponse(c, http.StatusBadRequest, "Неверный формат данных: "+err.Error())
+func (h *Handler) patchSong(c echo.Context, id int64) error {
+	patch, err := bindSongPatch(c)
+	if err != nil {
+		return err
 	}
 
-	updatedSong, err := h.service.UpdateSong(id, updateSong)
+	updatedSong, err := h.service.UpdateSong(c.Request().Context(), id, patch)
 	if err != nil {
-		if strings.Contains(err.Error(), "не найдена") || strings.Contains(err.Error(), "не указаны поля") {
-			return h.errorResponse(c, http.StatusBadRequest, err.Error())
-		}
-		return h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, model.Response{
 		Status:  "Success",
-		Message: "Песня обновлена",
+		Message: h.translate(c, i18n.SongUpdated),
 		Data:    updatedSong,
 	})
 }
 
 // GetVersesHandler возвращает текст песни с пагинацией по куплетам
 // @Summary Получить куплеты песни
-// @Description Возвращает текст песни с пагинацией по куплетам по указанному ID
+// @Description Возвращает текст песни с пагинацией по куплетам по указанному ID.
+// @Description В режиме stanzas куплеты разделяются пустыми строками, метки вида [Chorus] возвращаются в поле label.
+// @Description Режим lines сохраняет прежнее поведение: каждая строка считается куплетом
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param id path int true "ID песни"
+// @Param mode query string false "Что считать куплетом" Enums(stanzas, lines) default(stanzas)
 // @Param verse_page query int false "Номер страницы куплетов" default(1)
-// @Param verse_size query int false "Размер страницы куплетов" default(1)
-// @Success 200 {object} model.Response "Куплеты успешно получены"
-// @Failure 400 {object} model.Response "Неверный формат ID или страницы"
-// @Failure 500 {object} model.Response "Внутренняя ошибка сервера"
+// @Param verse_size query int false "Размер страницы куплетов (не больше 100)" default(1)
+// @Success 200 {object} model.Response{data=model.VerseResponse} "Куплеты успешно получены"
+// @Failure 400 {object} model.Problem "Неверный формат ID или страницы"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав"
+// @Failure 404 {object} model.Problem "Песня не найдена"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs/{id}/verses [get]
 func (h *Handler) GetVersesHandler(c echo.Context) error {
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil || id <= 0 {
-		return h.errorResponse(c, http.StatusBadRequest, "укажите корректный ID песни")
+	defer startSpan(c, "GetVersesHandler").End()
+	id, err := parsePathID(c)
+	if err != nil {
+		return err
 	}
 
-	versePage, err := strconv.Atoi(c.QueryParam("verse_page"))
-	if err != nil || versePage < 1 {
-		versePage = 1
+	versePage, err := parsePositive(c, "verse_page", 1, 0)
+	if err != nil {
+		return err
+	}
+	// Один куплет на одну страницу по дефолту
+	verseSize, err := parsePositive(c, "verse_size", 1, maxPageSize)
+	if err != nil {
+		return err
 	}
 
-	verseSize, err := strconv.Atoi(c.QueryParam("verse_size"))
-	if err != nil || verseSize < 1 {
-		verseSize = 1 // Один куплет на одну страницу по дефолту
+	mode := model.VerseMode(c.QueryParam("mode"))
+	switch mode {
+	case "":
+		mode = model.VerseModeStanzas
+	case model.VerseModeLines, model.VerseModeStanzas:
+	default:
+		return service.NewFieldError("mode", i18n.InvalidParam, "mode")
 	}
 
-	resp, err := h.service.GetSongVerses(id, versePage, verseSize)
+	resp, err := h.service.GetSongVerses(c.Request().Context(), id, mode, versePage, verseSize)
 	if err != nil {
-		if strings.Contains(err.Error(), "не найдена") || strings.Contains(err.Error(), "превышает") {
-			return h.errorResponse(c, http.StatusBadRequest, err.Error())
-		}
-		return h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, model.Response{
 		Status:  "Success",
-		Message: "Куплеты получены",
+		Message: h.translate(c, i18n.VersesGot),
 		Data:    resp,
 	})
 }
 
 // SearchVersesHandler ищет куплеты по тексту
 // @Summary Поиск куплетов по тексту
-// @Description Ищет куплеты, содержащие указанный текст
+// @Description Полнотекстовый поиск по строкам текстов песен. Запрос понимает синтаксис websearch_to_tsquery
+// @Description (фразы в кавычках, OR, исключение через минус). Результаты упорядочены по релевантности
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param text query string true "Текст для поиска"
-// @Success 200 {object} model.Response "Куплеты успешно найдены"
-// @Failure 400 {object} model.Response "Текст для поиска не указан"
-// @Failure 404 {object} model.Response "Куплеты не найдены"
-// @Failure 500 {object} model.Response "Внутренняя ошибка сервера"
+// @Param page query int false "Номер страницы (не больше 100000)" default(1)
+// @Param page_size query int false "Размер страницы (не больше 100)" default(10)
+// @Success 200 {object} model.Response{data=model.VerseSearchResponse} "Куплеты успешно найдены"
+// @Failure 400 {object} model.Problem "Текст для поиска не указан или страница вне диапазона"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав"
+// @Failure 404 {object} model.Problem "Куплеты не найдены"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs/verses/search [get]
 func (h *Handler) SearchVersesHandler(c echo.Context) error {
+	defer startSpan(c, "SearchVersesHandler").End()
 	searchText := c.QueryParam("text")
 	if searchText == "" {
-		return h.errorResponse(c, http.StatusBadRequest, "укажите текст для поиска")
+		return service.NewFieldError("text", i18n.SearchTextRequired)
 	}
 
-	h.logger.Info("Handing GET /songs/verses/search", "text", searchText)
-	results, err := h.service.SearchVerses(searchText)
+	page, err := parsePositive(c, "page", 1, maxPage)
 	if err != nil {
-		if strings.Contains(err.Error(), "не найдены") {
-			return h.errorResponse(c, http.StatusNotFound, err.Error())
-		}
-		return h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return err
+	}
+	pageSize, err := parsePositive(c, "page_size", defaultPageSize, maxPageSize)
+	if err != nil {
+		return err
 	}
 
-	h.logger.Debug("Verses search completed", "count", len(results))
+	h.logger.InfoContext(c.Request().Context(), "Handing GET /songs/verses/search", "text", searchText)
+	results, err := h.service.SearchVerses(c.Request().Context(), searchText, page, pageSize)
+	if err != nil {
+		return err
+	}
+
+	h.logger.DebugContext(c.Request().Context(), "Verses search completed", "count", len(results.Items), "total", results.Total)
 	return c.JSON(http.StatusOK, model.Response{
 		Status:  "Success",
-		Message: "Куплеты найдены",
+		Message: h.translate(c, i18n.VersesFound),
 		Data:    results,
 	})
 }
+
+// SearchHandler ищет песни сразу по группе, названию и тексту с учётом опечаток
+// @Summary Нечёткий поиск песен
+// @Description Ищет песни по группе, названию и тексту с помощью триграммного сходства (pg_trgm).
+// @Description Для каждого результата возвращается оценка сходства, а если ничего не найдено — подсказки «возможно, вы имели в виду»
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param page query int false "Номер страницы (не больше 100000)" default(1)
+// @Param page_size query int false "Размер страницы (не больше 100)" default(10)
+// @Success 200 {object} model.SearchResponse
+// @Failure 400 {object} model.Problem "Запрос не указан или страница вне диапазона"
+// @Failure 401 {object} model.Problem "Нет действительного ключа API или токена"
+// @Failure 403 {object} model.Problem "Недостаточно прав"
+// @Failure 429 {object} model.Problem "Превышен лимит запросов, см. Retry-After"
+// @Failure 500 {object} model.Problem "Внутренняя ошибка сервера"
+// @Failure 503 {object} model.Problem "Хранилище недоступно"
+// @Failure 504 {object} model.Problem "Хранилище не ответило вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /search [get]
+func (h *Handler) SearchHandler(c echo.Context) error {
+	defer startSpan(c, "SearchHandler").End()
+	q := strings.TrimSpace(c.QueryParam("q"))
+	if q == "" {
+		return service.NewFieldError("q", i18n.SearchTextRequired)
+	}
+
+	page, err := parsePositive(c, "page", 1, maxPage)
+	if err != nil {
+		return err
+	}
+	pageSize, err := parsePositive(c, "page_size", defaultPageSize, maxPageSize)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.service.Search(c.Request().Context(), q, page, pageSize)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,508 @@
+package handler
+
+import (
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+var testSongs = []model.Song{
+	{Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: "2006-07-16",
+		Text: "[Verse]\nOoh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\n[Chorus]\nGlaciers melting in the dead of night\nAnd the superstars sucked into the supermassive"},
+	{Group: "Muse", Song: "Uprising", ReleaseDate: "2009-09-07",
+		Text: "Paranoia is in bloom\nThe PR transmissions will resume"},
+	{Group: "Queen", Song: "Bohemian Rhapsody", ReleaseDate: "1975-10-31",
+		Text: "Is this the real life?\nIs this just fantasy?"},
+	{Group: "Queen", Song: "Another One Bites the Dust", ReleaseDate: "1980-08-22"},
+	{Group: "The Beatles", Song: "Let It Be",
+		Text: "When I find myself in times of trouble\nMother Mary comes to me"},
+}
+
+// newTestServer собирает маршруты песен, как main, поверх хранилища в памяти
+// и без аутентификации
+func newTestServer(t *testing.T) *echo.Echo {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+	repo := repository.NewMemorySongRepository(0.3, slices.Clone(testSongs)...)
+	svc := service.NewSongService(repo, nil, service.NewCursorCodec([]byte("test")), service.Timeouts{}, nil, logger)
+	h := NewHandler(svc, i18n.English, logger)
+
+	e := echo.New()
+	e.HTTPErrorHandler = h.HTTPErrorHandler
+	e.GET("/songs", h.GetHandler)
+	e.POST("/songs", h.PostHandler)
+	e.GET("/songs/:id", h.GetSongHandler)
+	e.PUT("/songs/:id", h.PutHandler)
+	e.PATCH("/songs/:id", h.PatchHandler)
+	e.DELETE("/songs/:id", h.DeleteHandler)
+	e.DELETE("/songs", h.LegacyDeleteHandler)
+	e.PATCH("/songs", h.LegacyPatchHandler)
+	e.GET("/songs/:id/verses", h.GetVersesHandler)
+	e.GET("/songs/verses/search", h.SearchVersesHandler)
+	e.GET("/search", h.SearchHandler)
+	return e
+}
+
+// serve выполняет запрос; headers — пары имя, значение
+func serve(e *echo.Echo, method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %T from %q: %v", v, rec.Body.String(), err)
+	}
+	return v
+}
+
+// songData разбирает поле data ответа model.Response
+func songData(t *testing.T, rec *httptest.ResponseRecorder) model.Song {
+	t.Helper()
+	var resp struct {
+		Status string     `json:"status"`
+		Data   model.Song `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return resp.Data
+}
+
+func songIDs(songs []model.Song) []int64 {
+	ids := make([]int64, len(songs))
+	for i, song := range songs {
+		ids[i] = song.ID
+	}
+	return ids
+}
+
+func expectProblem(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) model.Problem {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d, body %s", rec.Code, status, rec.Body.String())
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != MIMEProblemJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, MIMEProblemJSON)
+	}
+	problem := decode[model.Problem](t, rec)
+	if problem.Code != code {
+		t.Errorf("code = %q, want %q (detail %q)", problem.Code, code, problem.Detail)
+	}
+	return problem
+}
+
+func TestListSongsFiltering(t *testing.T) {
+	e := newTestServer(t)
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"", []int64{1, 2, 3, 4, 5}},
+		{"id=2", []int64{2}},
+		{"group=MUSE", []int64{1, 2}},
+		{"song=the", []int64{4}},
+		{"group=quen&fuzzy=true", []int64{3, 4}},
+		{"release_from=1980-01-01&release_to=2006-12-31", []int64{1, 4}},
+		{"release_from=01.01.2007", []int64{2}},
+		{"ids=5,3,3", []int64{3, 5}},
+		{"group=que&match=prefix", []int64{3, 4}},
+		{"group=que&match=exact", nil},
+		{"song=let%20it%20be&match=exact", []int64{5}},
+		{"lyrics=FANTASY", []int64{3}},
+		{"has_text=false", []int64{4}},
+		{"has_text=true&group=queen", []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := serve(e, http.MethodGet, "/songs?"+tt.query, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+			}
+			resp := decode[model.SongsResponse](t, rec)
+			if got := songIDs(resp.Items); !slices.Equal(got, tt.want) && (len(got) != 0 || len(tt.want) != 0) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+			if resp.Total == nil || *resp.Total != len(tt.want) {
+				t.Errorf("total = %v, want %d", resp.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestListSongsPagination(t *testing.T) {
+	e := newTestServer(t)
+
+	t.Run("page numbers", func(t *testing.T) {
+		resp := decode[model.SongsResponse](t, serve(e, http.MethodGet, "/songs?page=2&page_size=2", ""))
+		if got := songIDs(resp.Items); !slices.Equal(got, []int64{3, 4}) {
+			t.Errorf("ids = %v, want [3 4]", got)
+		}
+		if resp.Page != 2 || resp.Total == nil || *resp.Total != 5 || resp.TotalPages == nil || *resp.TotalPages != 3 {
+			t.Errorf("page, total, totalPages = %d, %v, %v, want 2, 5, 3", resp.Page, resp.Total, resp.TotalPages)
+		}
+		expectProblem(t, serve(e, http.MethodGet, "/songs?page=4&page_size=2", ""), http.StatusBadRequest, service.CodePageOutOfRange)
+	})
+
+	t.Run("cursors", func(t *testing.T) {
+		first := decode[model.SongsResponse](t, serve(e, http.MethodGet, "/songs?page_size=2&include_total=false", ""))
+		if got := songIDs(first.Items); !slices.Equal(got, []int64{1, 2}) || first.NextCursor == "" || first.PrevCursor != "" || first.Total != nil {
+			t.Fatalf("first page = %+v", first)
+		}
+		second := decode[model.SongsResponse](t, serve(e, http.MethodGet, "/songs?page_size=2&include_total=false&cursor="+first.NextCursor, ""))
+		if got := songIDs(second.Items); !slices.Equal(got, []int64{3, 4}) || second.PrevCursor == "" {
+			t.Fatalf("second page = %+v", second)
+		}
+		back := decode[model.SongsResponse](t, serve(e, http.MethodGet, "/songs?page_size=2&include_total=false&cursor="+second.PrevCursor, ""))
+		if got := songIDs(back.Items); !slices.Equal(got, []int64{1, 2}) || back.PrevCursor != "" {
+			t.Errorf("previous page = %+v", back)
+		}
+
+		expectProblem(t, serve(e, http.MethodGet, "/songs?group=muse&cursor="+first.NextCursor, ""), http.StatusBadRequest, service.CodeValidationFailed)
+		expectProblem(t, serve(e, http.MethodGet, "/songs?cursor=garbage", ""), http.StatusBadRequest, service.CodeValidationFailed)
+		expectProblem(t, serve(e, http.MethodGet, "/songs?page=2&cursor="+first.NextCursor, ""), http.StatusBadRequest, service.CodeValidationFailed)
+	})
+}
+
+func TestListSongsSorting(t *testing.T) {
+	e := newTestServer(t)
+	tests := []struct {
+		sort string
+		want []int64
+	}{
+		{"group,-release_date", []int64{2, 1, 4, 3, 5}},
+		{"-group,song", []int64{5, 4, 3, 1, 2}},
+		{"release_date", []int64{5, 3, 4, 1, 2}},
+		{"-id", []int64{5, 4, 3, 2, 1}},
+		{"id,group", []int64{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			resp := decode[model.SongsResponse](t, serve(e, http.MethodGet, "/songs?sort="+tt.sort, ""))
+			if got := songIDs(resp.Items); !slices.Equal(got, tt.want) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+
+			// Курсоры продолжают выдачу в том же порядке
+			var got []int64
+			target := "/songs?page_size=2&sort=" + tt.sort
+			for target != "" {
+				page := decode[model.SongsResponse](t, serve(e, http.MethodGet, target, ""))
+				got = append(got, songIDs(page.Items)...)
+				target = ""
+				if page.NextCursor != "" {
+					target = "/songs?page_size=2&sort=" + tt.sort + "&cursor=" + page.NextCursor
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ids by cursor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	first := decode[model.SongsResponse](t, serve(e, http.MethodGet, "/songs?page_size=2&sort=group", ""))
+	expectProblem(t, serve(e, http.MethodGet, "/songs?page_size=2&sort=song&cursor="+first.NextCursor, ""), http.StatusBadRequest, service.CodeValidationFailed)
+}
+
+func TestListSongsFields(t *testing.T) {
+	e := newTestServer(t)
+	tests := []struct {
+		query string
+		want  model.Song
+	}{
+		{"", model.Song{ID: 3, Group: "Queen", Song: "Bohemian Rhapsody", ReleaseDate: "1975-10-31"}},
+		{"include=text", model.Song{ID: 3, Group: "Queen", Song: "Bohemian Rhapsody", ReleaseDate: "1975-10-31",
+			Text: "Is this the real life?\nIs this just fantasy?"}},
+		{"fields=group,song", model.Song{ID: 3, Group: "Queen", Song: "Bohemian Rhapsody"}},
+		{"fields=id,song&include=text", model.Song{ID: 3, Song: "Bohemian Rhapsody", Text: "Is this the real life?\nIs this just fantasy?"}},
+		{"fields=release_date,release_date", model.Song{ID: 3, ReleaseDate: "1975-10-31"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			for _, paging := range []string{"ids=3", "ids=3&page=1"} {
+				rec := serve(e, http.MethodGet, "/songs?"+paging+"&"+tt.query, "")
+				resp := decode[model.SongsResponse](t, rec)
+				if len(resp.Items) != 1 || resp.Items[0] != tt.want {
+					t.Errorf("%s: items = %+v, want %+v", paging, resp.Items, tt.want)
+				}
+			}
+		})
+	}
+
+	rec := serve(e, http.MethodGet, "/songs?ids=3&fields=song", "")
+	if body := rec.Body.String(); !strings.Contains(body, `{"ID":3,"song":"Bohemian Rhapsody"}`) {
+		t.Errorf("sparse song = %s", body)
+	}
+
+	// Песня вне списка, как и раньше, всегда содержит group и song
+	rec = serve(e, http.MethodGet, "/songs/3", "")
+	if body := rec.Body.String(); !strings.Contains(body, `"group":"Queen","song":"Bohemian Rhapsody"`) {
+		t.Errorf("single song = %s", body)
+	}
+	if data, _ := json.Marshal(model.Song{ID: 1}); string(data) != `{"ID":1,"group":"","song":""}` {
+		t.Errorf("empty song = %s, want group and song present", data)
+	}
+}
+
+func TestListSongsValidation(t *testing.T) {
+	e := newTestServer(t)
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"sort=text", "sort"},
+		{"sort=group,", "sort"},
+		{"sort=group,-group", "sort"},
+		{"match=fuzzy", "match"},
+		{"group=que&match=exact&fuzzy=true", "fuzzy"},
+		{"ids=1,x", "ids"},
+		{"ids=0", "ids"},
+		{"id=1&ids=2", "ids"},
+		{"ids=" + strings.Repeat("1,", maxFilterIDs) + "1", "ids"},
+		{"has_text=maybe", "has_text"},
+		{"page=0", "page"},
+		{"page=abc", "page"},
+		{"page_size=-1", "page_size"},
+		{"page_size=ten", "page_size"},
+		{"page_size=101", "page_size"},
+		{"page=922337203685477582", "page"},
+		{"fields=group,lyrics", "fields"},
+		{"include=link", "include"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			problem := expectProblem(t, serve(e, http.MethodGet, "/songs?"+tt.query, ""), http.StatusBadRequest, service.CodeValidationFailed)
+			if len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field {
+				t.Errorf("errors = %+v, want field %q", problem.Errors, tt.field)
+			}
+		})
+	}
+}
+
+// TestPagingValidation проверяет, что параметры страниц поиска и куплетов
+// не заменяются молча значениями по умолчанию
+func TestPagingValidation(t *testing.T) {
+	e := newTestServer(t)
+	tests := []struct {
+		target string
+		field  string
+	}{
+		{"/songs/verses/search?text=baby&page=0", "page"},
+		{"/songs/verses/search?text=baby&page=x", "page"},
+		{"/songs/verses/search?text=baby&page_size=0", "page_size"},
+		{"/songs/verses/search?text=baby&page_size=101", "page_size"},
+		{"/songs/verses/search?text=baby&page=922337203685477582", "page"},
+		{"/songs/verses/search?text=baby&page=100001", "page"},
+		{"/search?q=queen&page=-1", "page"},
+		{"/search?q=queen&page_size=1.5", "page_size"},
+		{"/search?q=queen&page_size=1000", "page_size"},
+		{"/search?q=queen&page=922337203685477582&page_size=100", "page"},
+		{"/songs/1/verses?verse_page=0", "verse_page"},
+		{"/songs/1/verses?verse_page=first", "verse_page"},
+		{"/songs/1/verses?verse_size=-2", "verse_size"},
+		{"/songs/1/verses?verse_size=101", "verse_size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			problem := expectProblem(t, serve(e, http.MethodGet, tt.target, ""), http.StatusBadRequest, service.CodeValidationFailed)
+			if len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field {
+				t.Errorf("errors = %+v, want field %q", problem.Errors, tt.field)
+			}
+		})
+	}
+
+	for _, target := range []string{"/songs?page_size=100", "/search?q=queen&page_size=100", "/songs/verses/search?text=baby&page_size=100"} {
+		if rec := serve(e, http.MethodGet, target, ""); rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d, body %s", target, rec.Code, http.StatusOK, rec.Body.String())
+		}
+	}
+
+	// Наибольший допустимый номер страницы доходит до сервиса и не переполняет смещение
+	for _, target := range []string{"/songs?page=100000&page_size=100", "/search?q=queen&page=100000&page_size=100", "/songs/verses/search?text=baby&page=100000&page_size=100"} {
+		expectProblem(t, serve(e, http.MethodGet, target, ""), http.StatusBadRequest, service.CodePageOutOfRange)
+	}
+}
+
+func TestSongVerses(t *testing.T) {
+	e := newTestServer(t)
+
+	rec := serve(e, http.MethodGet, "/songs/1/verses?verse_page=2", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data model.VerseResponse `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Data.TotalVerses != 2 || len(resp.Data.Stanzas) != 1 || resp.Data.Stanzas[0].Label != "Chorus" {
+		t.Errorf("stanzas page 2 = %+v", resp.Data)
+	}
+
+	rec = serve(e, http.MethodGet, "/songs/2/verses?mode=lines&verse_size=5", "")
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Data.Mode != model.VerseModeLines || len(resp.Data.Verses) != 2 {
+		t.Errorf("lines = %+v", resp.Data)
+	}
+
+	expectProblem(t, serve(e, http.MethodGet, "/songs/2/verses?verse_page=3", ""), http.StatusBadRequest, service.CodePageOutOfRange)
+	expectProblem(t, serve(e, http.MethodGet, "/songs/2/verses?mode=words", ""), http.StatusBadRequest, service.CodeValidationFailed)
+	expectProblem(t, serve(e, http.MethodGet, "/songs/42/verses", ""), http.StatusNotFound, service.CodeSongNotFound)
+}
+
+func TestSearchVerses(t *testing.T) {
+	e := newTestServer(t)
+
+	rec := serve(e, http.MethodGet, "/songs/verses/search?text="+url.QueryEscape("glaciers melting"), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data model.VerseSearchResponse `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Data.Total != 1 || resp.Data.Items[0].SongID != 1 || resp.Data.Items[0].Label != "Chorus" || resp.Data.Items[0].Stanza != 2 {
+		t.Errorf("search result = %+v", resp.Data)
+	}
+
+	expectProblem(t, serve(e, http.MethodGet, "/songs/verses/search?text=nothing+here", ""), http.StatusNotFound, service.CodeVersesNotFound)
+	expectProblem(t, serve(e, http.MethodGet, "/songs/verses/search", ""), http.StatusBadRequest, service.CodeValidationFailed)
+}
+
+func TestPatchSong(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+		code   string
+		want   model.Song
+	}{
+		{
+			name: "absent fields are kept", target: "/songs/2", body: `{"link":"https://example.com"}`, status: http.StatusOK,
+			want: model.Song{ID: 2, Group: "Muse", Song: "Uprising", ReleaseDate: "2009-09-07", Link: "https://example.com",
+				Text: "Paranoia is in bloom\nThe PR transmissions will resume"},
+		},
+		{
+			name: "null clears field", target: "/songs/2", body: `{"release_date":null,"text":null}`, status: http.StatusOK,
+			want: model.Song{ID: 2, Group: "Muse", Song: "Uprising"},
+		},
+		{
+			name: "date is normalized", target: "/songs/4", body: `{"release_date":"22.08.1980","song":"Another One"}`, status: http.StatusOK,
+			want: model.Song{ID: 4, Group: "Queen", Song: "Another One", ReleaseDate: "1980-08-22"},
+		},
+		{name: "group cannot be cleared", target: "/songs/2", body: `{"group":null}`, status: http.StatusBadRequest, code: service.CodeValidationFailed},
+		{name: "empty patch", target: "/songs/2", body: `{}`, status: http.StatusBadRequest, code: service.CodeNoFieldsToUpdate},
+		{name: "body is not an object", target: "/songs/2", body: `[1]`, status: http.StatusBadRequest, code: service.CodeMalformedBody},
+		{name: "invalid date", target: "/songs/2", body: `{"release_date":"tomorrow"}`, status: http.StatusBadRequest, code: service.CodeValidationFailed},
+		{name: "unknown song", target: "/songs/42", body: `{"link":"x"}`, status: http.StatusNotFound, code: service.CodeSongNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestServer(t)
+			rec := serve(e, http.MethodPatch, tt.target, tt.body, echo.HeaderContentType, "application/merge-patch+json")
+			if tt.code != "" {
+				expectProblem(t, rec, tt.status, tt.code)
+				return
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+			}
+			if got := songData(t, rec); got != tt.want {
+				t.Errorf("patched song = %+v, want %+v", got, tt.want)
+			}
+			if got := songData(t, serve(e, http.MethodGet, tt.target, "")); got != tt.want {
+				t.Errorf("stored song = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	e := newTestServer(t)
+
+	rec := serve(e, http.MethodPatch, "/songs?id=3", `{"link":"x"}`)
+	if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "true" || !strings.Contains(rec.Header().Get("Link"), "</songs/3>") {
+		t.Errorf("legacy PATCH: status %d, headers %v", rec.Code, rec.Header())
+	}
+	rec = serve(e, http.MethodDelete, "/songs?id=3", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "true" {
+		t.Errorf("legacy DELETE: status %d, headers %v", rec.Code, rec.Header())
+	}
+	expectProblem(t, serve(e, http.MethodGet, "/songs/3", ""), http.StatusNotFound, service.CodeSongNotFound)
+}
+
+func TestErrorMapping(t *testing.T) {
+	e := newTestServer(t)
+
+	t.Run("problem details", func(t *testing.T) {
+		problem := expectProblem(t, serve(e, http.MethodGet, "/songs/42", ""), http.StatusNotFound, service.CodeSongNotFound)
+		if problem.Type != "urn:songs-api:problem:song_not_found" || problem.Instance != "/songs/42" || problem.Detail != "song with ID 42 not found" {
+			t.Errorf("problem = %+v", problem)
+		}
+	})
+
+	t.Run("field errors", func(t *testing.T) {
+		problem := expectProblem(t, serve(e, http.MethodPost, "/songs", `{"release_date":"soon"}`), http.StatusBadRequest, service.CodeValidationFailed)
+		var fields []string
+		for _, f := range problem.Errors {
+			fields = append(fields, f.Field)
+		}
+		if !slices.Equal(fields, []string{"group", "song", "release_date"}) {
+			t.Errorf("fields = %v", fields)
+		}
+	})
+
+	t.Run("language negotiation", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/songs/42", "", "Accept-Language", "ru-RU,ru;q=0.9")
+		problem := expectProblem(t, rec, http.StatusNotFound, service.CodeSongNotFound)
+		if problem.Detail != "песня с ID 42 не найдена" || rec.Header().Get("Content-Language") != i18n.Russian {
+			t.Errorf("detail = %q, Content-Language = %q", problem.Detail, rec.Header().Get("Content-Language"))
+		}
+	})
+
+	t.Run("legacy error format", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/songs/abc", "", echo.HeaderAccept, echo.MIMEApplicationJSON)
+		resp := decode[model.Response](t, rec)
+		if rec.Code != http.StatusBadRequest || resp.Status != "Error" || resp.Message == "" {
+			t.Errorf("status %d, response %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("routing errors", func(t *testing.T) {
+		expectProblem(t, serve(e, http.MethodGet, "/nowhere", ""), http.StatusNotFound, codeRouteNotFound)
+		expectProblem(t, serve(e, http.MethodPost, "/songs/1", ""), http.StatusMethodNotAllowed, codeMethodNotAllowed)
+	})
+
+	t.Run("internal error detail is not exposed", func(t *testing.T) {
+		problem := problemFromError(errors.New(`ошибка выполнения запроса: pq: relation "songs" does not exist`), i18n.English)
+		if problem.Status != http.StatusInternalServerError || problem.Code != codeInternalError || problem.Detail != "internal server error, please retry later" {
+			t.Errorf("problem = %+v", problem)
+		}
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		expectProblem(t, serve(e, http.MethodPut, "/songs/1", `{"group":`), http.StatusBadRequest, service.CodeMalformedBody)
+	})
+}
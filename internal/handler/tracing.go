@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("awesomeProject/internal/handler")
+
+// startSpan открывает спан обработчика и подменяет контекст запроса, чтобы
+// спаны сервиса и базы данных стали его потомками
+func startSpan(c echo.Context, name string) trace.Span {
+	ctx, span := tracer.Start(c.Request().Context(), "Handler."+name)
+	c.SetRequest(c.Request().WithContext(ctx))
+	return span
+}
@@ -0,0 +1,122 @@
+// Package health проверяет готовность сервиса принимать запросы
+package health
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Имена проверок в ответе /readyz
+const (
+	CheckShutdown   = "shutdown"
+	CheckDatabase   = "database"
+	CheckMigrations = "migrations"
+)
+
+var errShuttingDown = errors.New("сервис останавливается")
+
+// unavailable — текст ошибки проверки в ответе /readyz. Проверка открыта без
+// аутентификации, поэтому подробности (адрес базы, текст ошибки драйвера)
+// пишутся только в журнал.
+const unavailable = "unavailable"
+
+type Checker struct {
+	db *sql.DB
+	// expectedVersion — номер последней встроенной миграции
+	expectedVersion uint
+	timeout         time.Duration
+	shuttingDown    atomic.Bool
+	logger          *slog.Logger
+}
+
+func NewChecker(db *sql.DB, expectedVersion uint, timeout time.Duration, logger *slog.Logger) *Checker {
+	return &Checker{db: db, expectedVersion: expectedVersion, timeout: timeout, logger: logger}
+}
+
+// SetShuttingDown переводит проверку готовности в состояние отказа, чтобы
+// балансировщик перестал направлять запросы до остановки сервера
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready выполняет все проверки готовности. Второе значение ложно, если хотя бы
+// одна проверка не прошла.
+func (c *Checker) Ready(ctx context.Context) (model.HealthResponse, bool) {
+	resp := model.HealthResponse{Status: model.HealthStatusOK, Checks: make(map[string]model.HealthCheck)}
+	checks := []struct {
+		name string
+		fn   func(ctx context.Context) error
+	}{
+		{CheckShutdown, c.checkShutdown},
+		{CheckDatabase, c.checkDatabase},
+		{CheckMigrations, c.checkMigrations},
+	}
+	for _, check := range checks {
+		result, err := run(ctx, c.timeout, check.fn)
+		if err != nil {
+			c.logger.WarnContext(ctx, "Readiness check failed", "check", check.name, "error", err)
+			resp.Status = model.HealthStatusFail
+		}
+		resp.Checks[check.name] = result
+	}
+	return resp, resp.Status == model.HealthStatusOK
+}
+
+// run выполняет проверку с таймаутом и замеряет её длительность. Ошибка
+// проверки возвращается отдельно для журнала, в результат попадает только
+// unavailable.
+func run(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) (model.HealthCheck, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	result := model.HealthCheck{
+		Status:    model.HealthStatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = model.HealthStatusFail
+		result.Error = unavailable
+	}
+	return result, err
+}
+
+func (c *Checker) checkShutdown(context.Context) error {
+	if c.shuttingDown.Load() {
+		return errShuttingDown
+	}
+	return nil
+}
+
+func (c *Checker) checkDatabase(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("база данных недоступна: %v", err)
+	}
+	return nil
+}
+
+// checkMigrations сверяет версию схемы с последней встроенной миграцией.
+// Таблица schema_migrations ведётся golang-migrate одинаково для всех драйверов.
+func (c *Checker) checkMigrations(ctx context.Context) error {
+	var version uint
+	var dirty bool
+	err := c.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("миграции не применены, ожидается версия %d", c.expectedVersion)
+	case err != nil:
+		return fmt.Errorf("ошибка получения версии схемы: %v", err)
+	case dirty:
+		return fmt.Errorf("миграция %d применена не полностью (dirty)", version)
+	case version != c.expectedVersion:
+		return fmt.Errorf("версия схемы %d, ожидается %d", version, c.expectedVersion)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Russian = "ru"
+	English = "en"
+)
+
+// DefaultLanguage используется для логов и в качестве последнего запасного варианта
+const DefaultLanguage = Russian
+
+func IsSupported(lang string) bool {
+	_, ok := messages[lang]
+	return ok
+}
+
+// Translate возвращает сообщение на языке lang. Если ключа нет в этом языке,
+// используется DefaultLanguage, если нет и там — сам ключ.
+func Translate(lang, key string, args ...interface{}) string {
+	format, ok := messages[lang][key]
+	if !ok {
+		format, ok = messages[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Negotiate выбирает поддерживаемый язык по заголовку Accept-Language
+// (RFC 9110, раздел 12.5.4). Если подходящего нет, возвращается fallback.
+func Negotiate(acceptLanguage, fallback string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.TrimSpace(name) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{lang: tag, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, c := range candidates {
+		if c.lang == "*" {
+			return fallback
+		}
+		primary, _, _ := strings.Cut(c.lang, "-")
+		if IsSupported(primary) {
+			return primary
+		}
+	}
+	return fallback
+}
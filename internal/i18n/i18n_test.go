@@ -0,0 +1,34 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header   string
+		fallback string
+		want     string
+	}{
+		{"", English, English},
+		{"en", Russian, English},
+		{"ru-RU,ru;q=0.9,en;q=0.8", English, Russian},
+		{"en-US", Russian, English},
+		{"EN-gb", Russian, English},
+		{"de-DE,en;q=0.5", Russian, English},
+		{"ru;q=0.3,en;q=0.7", Russian, English},
+		{"en;q=0,ru;q=0.1", English, Russian},
+		{"en;q=0", Russian, Russian},
+		{"*", English, English},
+		{"de,*;q=0.5,en;q=0.1", Russian, Russian},
+		{"fr, de", English, English},
+		{";;;,,", English, English},
+		{"en;q=abc", Russian, English},
+		{"q=0.5;en", Russian, Russian},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := Negotiate(tt.header, tt.fallback); got != tt.want {
+				t.Errorf("Negotiate(%q, %q) = %q, want %q", tt.header, tt.fallback, got, tt.want)
+			}
+		})
+	}
+}
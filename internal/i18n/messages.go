@@ -0,0 +1,162 @@
+package i18n
+
+// Ключи сообщений. Для ошибок ключ обычно совпадает с кодом ошибки.
+const (
+	SongAdded    = "song_added"
+	SongFound    = "song_found"
+	SongReplaced = "song_replaced"
+	SongUpdated  = "song_updated"
+	SongDeleted  = "song_deleted"
+	VersesGot    = "verses_fetched"
+	VersesFound  = "verses_found"
+
+	SongNotFound        = "song_not_found"
+	VersesNotFound      = "verses_not_found"
+	PageOutOfRange      = "page_out_of_range"
+	VersePageOutOfRange = "verse_page_out_of_range"
+	NoFieldsToUpdate    = "no_fields_to_update"
+	Conflict            = "conflict"
+	InvalidSong         = "invalid_song"
+	InvalidID           = "invalid_id"
+	MalformedBody       = "malformed_body"
+	BodyNotObject       = "body_not_object"
+	GroupRequired       = "group_required"
+	SongRequired        = "song_required"
+	InvalidDate         = "invalid_date"
+	InvalidReleaseRange = "invalid_release_range"
+	SearchTextRequired  = "search_text_required"
+	InvalidParam        = "invalid_param"
+	ParamTooLarge       = "param_too_large"
+	Timeout             = "timeout"
+	InternalError       = "internal_error"
+	Unavailable         = "service_unavailable"
+	Unauthorized        = "unauthorized"
+	Forbidden           = "forbidden"
+	RoleRequired        = "role_required"
+	SongNotOwned        = "song_not_owned"
+	UserNotFound        = "user_not_found"
+	UserNameRequired    = "user_name_required"
+	InvalidRole         = "invalid_role"
+	InvalidUser         = "invalid_user"
+	UserCreated         = "user_created"
+	UserFound           = "user_found"
+	UserDeleted         = "user_deleted"
+	RoleAssigned        = "role_assigned"
+	RateLimited         = "rate_limited"
+	InvalidCursor       = "invalid_cursor"
+	CursorWithPage      = "cursor_with_page"
+	IDWithIDs           = "id_with_ids"
+	InvalidIDList       = "invalid_id_list"
+	TooManyIDs          = "too_many_ids"
+	FuzzyWithMatch      = "fuzzy_with_match"
+	InvalidSortField    = "invalid_sort_field"
+	DuplicateSortField  = "duplicate_sort_field"
+	InvalidFieldName    = "invalid_field_name"
+)
+
+var messages = map[string]map[string]string{
+	Russian: {
+		SongAdded:    "Песня добавлена",
+		SongFound:    "Песня найдена",
+		SongReplaced: "Песня заменена",
+		SongUpdated:  "Песня обновлена",
+		SongDeleted:  "Песня удалена",
+		VersesGot:    "Куплеты получены",
+		VersesFound:  "Куплеты найдены",
+
+		SongNotFound:        "песня с ID %d не найдена",
+		VersesNotFound:      "куплеты с текстом %q не найдены",
+		PageOutOfRange:      "запрошенная страница превышает количество страниц",
+		VersePageOutOfRange: "запрошенная страница куплетов превышает общее кол-во страниц",
+		NoFieldsToUpdate:    "не указаны поля для обновления",
+		Conflict:            "такая запись уже существует",
+		InvalidSong:         "Неверные данные песни",
+		InvalidID:           "укажите корректный ID песни",
+		MalformedBody:       "Неверный формат данных: %s",
+		BodyNotObject:       "Неверный формат данных: ожидается JSON-объект",
+		GroupRequired:       "группа не может быть пустой",
+		SongRequired:        "название песни не может быть пустым",
+		InvalidDate:         "неверный формат даты %q, ожидается ГГГГ-ММ-ДД",
+		InvalidReleaseRange: "release_from не может быть позже release_to",
+		SearchTextRequired:  "укажите текст для поиска",
+		InvalidParam:        "некорректное значение параметра %s",
+		ParamTooLarge:       "значение параметра %s не должно превышать %d",
+		Timeout:             "хранилище не ответило вовремя, повторите запрос позже",
+		InternalError:       "внутренняя ошибка сервера, повторите запрос позже",
+		Unavailable:         "хранилище временно недоступно, повторите запрос позже",
+		Unauthorized:        "требуется действительный ключ API или токен",
+		Forbidden:           "недостаточно прав: требуется %s",
+		RoleRequired:        "действие доступно только ролям %s",
+		SongNotOwned:        "песню с ID %d может изменить только её автор или администратор",
+		UserNotFound:        "пользователь с ID %d не найден",
+		UserNameRequired:    "имя пользователя обязательно",
+		InvalidRole:         "неизвестная роль %q, допустимы viewer, editor, admin",
+		InvalidUser:         "Неверные данные пользователя",
+		UserCreated:         "Пользователь создан",
+		UserFound:           "Пользователь найден",
+		UserDeleted:         "Пользователь удалён",
+		RoleAssigned:        "Роль назначена",
+		RateLimited:         "слишком много запросов, повторите через %d с",
+		InvalidCursor:       "курсор повреждён, устарел или выдан для других условий поиска",
+		CursorWithPage:      "параметры cursor и page нельзя указывать вместе",
+		IDWithIDs:           "параметры id и ids нельзя указывать вместе",
+		InvalidIDList:       "некорректный ID %q в списке ids",
+		TooManyIDs:          "в ids можно указать не больше %d ID",
+		FuzzyWithMatch:      "fuzzy применяется только с match=contains",
+		InvalidSortField:    "нельзя сортировать по %q, допустимы поля %s",
+		DuplicateSortField:  "поле %s указано в sort несколько раз",
+		InvalidFieldName:    "неизвестное поле %q в %s, допустимы %s",
+	},
+	English: {
+		SongAdded:    "Song added",
+		SongFound:    "Song found",
+		SongReplaced: "Song replaced",
+		SongUpdated:  "Song updated",
+		SongDeleted:  "Song deleted",
+		VersesGot:    "Verses fetched",
+		VersesFound:  "Verses found",
+
+		SongNotFound:        "song with ID %d not found",
+		VersesNotFound:      "no verses containing %q found",
+		PageOutOfRange:      "requested page exceeds the number of pages",
+		VersePageOutOfRange: "requested verse page exceeds the number of pages",
+		NoFieldsToUpdate:    "no fields to update",
+		Conflict:            "record already exists",
+		InvalidSong:         "Invalid song data",
+		InvalidID:           "provide a valid song ID",
+		MalformedBody:       "Malformed request body: %s",
+		BodyNotObject:       "Malformed request body: JSON object expected",
+		GroupRequired:       "group must not be empty",
+		SongRequired:        "song title must not be empty",
+		InvalidDate:         "invalid date %q, expected YYYY-MM-DD",
+		InvalidReleaseRange: "release_from must not be later than release_to",
+		SearchTextRequired:  "provide text to search for",
+		InvalidParam:        "invalid value of parameter %s",
+		ParamTooLarge:       "parameter %s must not exceed %d",
+		Timeout:             "storage did not respond in time, please retry later",
+		InternalError:       "internal server error, please retry later",
+		Unavailable:         "storage is temporarily unavailable, please retry later",
+		Unauthorized:        "a valid API key or token is required",
+		Forbidden:           "insufficient permissions: %s is required",
+		RoleRequired:        "this action is only available to roles %s",
+		SongNotOwned:        "song with ID %d can only be changed by its author or an administrator",
+		UserNotFound:        "user with ID %d not found",
+		UserNameRequired:    "user name is required",
+		InvalidRole:         "unknown role %q, expected viewer, editor or admin",
+		InvalidUser:         "Invalid user data",
+		UserCreated:         "User created",
+		UserFound:           "User found",
+		UserDeleted:         "User deleted",
+		RoleAssigned:        "Role assigned",
+		RateLimited:         "too many requests, retry in %d s",
+		InvalidCursor:       "cursor is malformed, expired or was issued for different search conditions",
+		CursorWithPage:      "cursor and page parameters cannot be combined",
+		IDWithIDs:           "id and ids parameters cannot be combined",
+		InvalidIDList:       "invalid ID %q in ids",
+		TooManyIDs:          "ids accepts at most %d IDs",
+		FuzzyWithMatch:      "fuzzy can only be combined with match=contains",
+		InvalidSortField:    "cannot sort by %q, allowed fields are %s",
+		DuplicateSortField:  "field %s is listed in sort more than once",
+		InvalidFieldName:    "unknown field %q in %s, allowed fields are %s",
+	},
+}
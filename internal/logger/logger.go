@@ -1,13 +1,83 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Форматы вывода логов
+const (
+	FormatJSON = "json"
+	FormatText = "text"
 )
 
-func NewLogger() *slog.Logger {
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	})
-	return slog.New(handler)
+// NewLogger создаёт логгер с уровнем level (debug, info, warn, error) и форматом
+// format. К каждой записи, сделанной с контекстом запроса, добавляются его
+// request_id и trace_id.
+func NewLogger(level, format string) *slog.Logger {
+	return slog.New(newHandler(os.Stdout, level, format))
+}
+
+func newHandler(w io.Writer, level, format string) slog.Handler {
+	lvl, _ := ParseLevel(level)
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	if format == FormatText {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return contextHandler{handler}
+}
+
+// ParseLevel разбирает название уровня; для неизвестного возвращает Info и false
+func ParseLevel(level string) (slog.Level, bool) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo, false
+	}
+	return lvl, true
+}
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет ID запроса в контексте
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает ID запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler дописывает в запись атрибуты из контекста
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
 }
@@ -0,0 +1,78 @@
+package lyrics
+
+import (
+	"awesomeProject/internal/model"
+	"regexp"
+	"strings"
+)
+
+// labelPattern распознаёт метки разделов вида [Chorus] или [Verse 2]
+var labelPattern = regexp.MustCompile(`^\[([^\[\]]+)\]$`)
+
+// Lines делит текст на строки так же, как API делало до появления куплетов
+func Lines(text string) []string {
+	return strings.Split(normalize(text), "\n")
+}
+
+// Parse делит текст на куплеты. Куплеты разделяются пустыми строками,
+// строка-метка вроде [Chorus] начинает новый куплет и становится его меткой.
+func Parse(text string) []model.Stanza {
+	stanzas, _ := parse(text)
+	return stanzas
+}
+
+// LineStanzas возвращает для каждой строки из Lines номер куплета,
+// к которому она относится, или 0 для пустых строк
+func LineStanzas(text string) []int {
+	_, lineStanzas := parse(text)
+	return lineStanzas
+}
+
+func parse(text string) ([]model.Stanza, []int) {
+	lines := Lines(text)
+	lineStanzas := make([]int, len(lines))
+	var stanzas []model.Stanza
+	var current *model.Stanza
+	var currentLines []int
+
+	flush := func() {
+		if current != nil {
+			if current.Lines == nil {
+				// Куплет из одной метки отдаётся как "lines": [], а не null
+				current.Lines = []string{}
+			}
+			current.Number = len(stanzas) + 1
+			stanzas = append(stanzas, *current)
+			for _, i := range currentLines {
+				lineStanzas[i] = current.Number
+			}
+		}
+		current = nil
+		currentLines = nil
+	}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+		if m := labelPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			current = &model.Stanza{Label: strings.TrimSpace(m[1])}
+			currentLines = append(currentLines, i)
+			continue
+		}
+		if current == nil {
+			current = &model.Stanza{}
+		}
+		current.Lines = append(current.Lines, line)
+		currentLines = append(currentLines, i)
+	}
+	flush()
+	return stanzas, lineStanzas
+}
+
+func normalize(text string) string {
+	return strings.ReplaceAll(text, "\r\n", "\n")
+}
@@ -0,0 +1,90 @@
+package lyrics
+
+import (
+	"awesomeProject/internal/model"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		want        []model.Stanza
+		lineStanzas []int
+	}{
+		{
+			name:        "empty text",
+			text:        "",
+			lineStanzas: []int{0},
+		},
+		{
+			name:        "stanzas without labels",
+			text:        "a\nb\n\nc",
+			want:        []model.Stanza{{Number: 1, Lines: []string{"a", "b"}}, {Number: 2, Lines: []string{"c"}}},
+			lineStanzas: []int{1, 1, 0, 2},
+		},
+		{
+			name: "labels start stanzas",
+			text: "[Verse 1]\na\n[ Chorus ]\nb\nc",
+			want: []model.Stanza{
+				{Number: 1, Label: "Verse 1", Lines: []string{"a"}},
+				{Number: 2, Label: "Chorus", Lines: []string{"b", "c"}},
+			},
+			lineStanzas: []int{1, 1, 2, 2, 2},
+		},
+		{
+			name:        "blank line runs and surrounding blanks",
+			text:        "\n\na\n\n\n\n  \nb\n\n",
+			want:        []model.Stanza{{Number: 1, Lines: []string{"a"}}, {Number: 2, Lines: []string{"b"}}},
+			lineStanzas: []int{0, 0, 1, 0, 0, 0, 0, 2, 0, 0},
+		},
+		{
+			name: "label without lines",
+			text: "[Intro]\n\n[Chorus]\nb",
+			want: []model.Stanza{
+				{Number: 1, Label: "Intro", Lines: []string{}},
+				{Number: 2, Label: "Chorus", Lines: []string{"b"}},
+			},
+			lineStanzas: []int{1, 0, 2, 2},
+		},
+		{
+			name:        "CRLF input",
+			text:        "[Verse]\r\na \r\nb\r\n\r\nc\r\n",
+			want:        []model.Stanza{{Number: 1, Label: "Verse", Lines: []string{"a", "b"}}, {Number: 2, Lines: []string{"c"}}},
+			lineStanzas: []int{1, 1, 1, 0, 2, 0},
+		},
+		{
+			name:        "brackets inside a line are not a label",
+			text:        "a [b] c",
+			want:        []model.Stanza{{Number: 1, Lines: []string{"a [b] c"}}},
+			lineStanzas: []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse = %#v, want %#v", got, tt.want)
+			}
+			if got := LineStanzas(tt.text); !slices.Equal(got, tt.lineStanzas) {
+				t.Errorf("LineStanzas = %v, want %v", got, tt.lineStanzas)
+			}
+			if got := Lines(tt.text); len(got) != len(tt.lineStanzas) {
+				t.Errorf("Lines = %q, want %d lines", got, len(tt.lineStanzas))
+			}
+		})
+	}
+}
+
+func TestLabelOnlyStanzaJSON(t *testing.T) {
+	data, err := json.Marshal(Parse("[Outro]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"lines":[]`) {
+		t.Errorf("json = %s, want empty lines array", data)
+	}
+}
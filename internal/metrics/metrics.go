@@ -0,0 +1,127 @@
+// Package metrics собирает метрики сервиса в формате Prometheus
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "songs_api"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	queryDuration *prometheus.HistogramVec
+	songsCreated  prometheus.Counter
+	songsDeleted  prometheus.Counter
+	emptySearches *prometheus.CounterVec
+}
+
+// New регистрирует метрики в отдельном реестре вместе со статистикой пула
+// соединений db и стандартными метриками процесса
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Storage query latency by service method and repository query.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"method", "query", "outcome"}),
+		songsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "songs_created_total",
+			Help:      "Number of songs created.",
+		}),
+		songsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "songs_deleted_total",
+			Help:      "Number of songs deleted.",
+		}),
+		emptySearches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "searches_zero_results_total",
+			Help:      "Number of searches that returned no results, by search kind.",
+		}, []string{"kind"}),
+	}
+	m.registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.queryDuration,
+		m.songsCreated,
+		m.songsDeleted,
+		m.emptySearches,
+		collectors.NewDBStatsCollector(db, "songs"),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler отдаёт метрики для /metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware считает запросы и их длительность по шаблону маршрута, а не по
+// фактическому пути, чтобы ID в пути не раздували число временных рядов
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			// Ошибки обработчиков уже переданы в HTTPErrorHandler внутренним
+			// middleware, поэтому статус ответа итоговый
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method := c.Request().Method
+			m.httpRequests.WithLabelValues(route, method, strconv.Itoa(c.Response().Status)).Inc()
+			m.httpDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// ObserveQuery записывает длительность обращения к хранилищу
+func (m *Metrics) ObserveQuery(method, query string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.queryDuration.WithLabelValues(method, query, outcome).Observe(duration.Seconds())
+}
+
+func (m *Metrics) SongCreated() {
+	m.songsCreated.Inc()
+}
+
+func (m *Metrics) SongDeleted() {
+	m.songsDeleted.Inc()
+}
+
+func (m *Metrics) EmptySearch(kind string) {
+	m.emptySearches.WithLabelValues(kind).Inc()
+}
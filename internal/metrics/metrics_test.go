@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	_ "modernc.org/sqlite"
+)
+
+func TestMiddlewareLabels(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m := New(db)
+
+	e := echo.New()
+	e.Use(m.Middleware())
+	e.GET("/songs/:id", func(c echo.Context) error {
+		if c.Param("id") == "7" {
+			return c.NoContent(http.StatusNotFound)
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+	for _, target := range []string{"/songs/42?secret=x", "/songs/43", "/songs/7"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`songs_api_http_requests_total{method="GET",route="/songs/:id",status="204"} 2`,
+		`songs_api_http_requests_total{method="GET",route="/songs/:id",status="404"} 1`,
+		`songs_api_http_request_duration_seconds_count{method="GET",route="/songs/:id"} 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics have no %s", want)
+		}
+	}
+	for _, leaked := range []string{"/songs/42", "/songs/7", "secret"} {
+		if strings.Contains(body, leaked) {
+			t.Errorf("metrics contain %q", leaked)
+		}
+	}
+}
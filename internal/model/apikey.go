@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// APIKey — описание ключа API без самого ключа: хранится только его хэш
+type APIKey struct {
+	ID     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	// UserID — пользователь, от имени которого действует ключ; его роль
+	// определяет, какие песни можно изменять
+	UserID    *int64     `json:"user_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout — формат, в котором API принимает и отдаёт даты
+const DateLayout = "2006-01-02"
+
+// inputDateLayouts перечисляет допустимые входные форматы дат.
+// Внешний сервис информации о песнях отдаёт даты в виде 16.07.2006.
+var inputDateLayouts = []string{DateLayout, "02.01.2006"}
+
+func ParseDate(value string) (time.Time, error) {
+	for _, layout := range inputDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверный формат даты %q, ожидается ГГГГ-ММ-ДД", value)
+}
+
+// NormalizeDate приводит дату к формату DateLayout
+func NormalizeDate(value string) (string, error) {
+	t, err := ParseDate(value)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(DateLayout), nil
+}
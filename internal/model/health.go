@@ -0,0 +1,20 @@
+package model
+
+// Статусы проверок здоровья
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthCheck — результат одной проверки
+type HealthCheck struct {
+	Status    string  `json:"status" enums:"ok,fail"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthResponse — ответ /healthz и /readyz
+type HealthResponse struct {
+	Status string                 `json:"status" enums:"ok,fail"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "encoding/json"
+
+// OptionalString различает три состояния поля в JSON Merge Patch (RFC 7386):
+// поле отсутствует, поле равно null и поле содержит значение.
+type OptionalString struct {
+	Set   bool
+	Null  bool
+	Value string
+}
+
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// SongPatch — тело PATCH-запроса. Отсутствующие поля не меняются,
+// null очищает поле, любое другое значение его заменяет.
+type SongPatch struct {
+	Group       OptionalString `json:"group" swaggertype:"string"`
+	Song        OptionalString `json:"song" swaggertype:"string"`
+	Text        OptionalString `json:"text" swaggertype:"string"`
+	ReleaseDate OptionalString `json:"release_date" swaggertype:"string" example:"2006-07-16"`
+	Link        OptionalString `json:"link" swaggertype:"string"`
+}
+
+func (p SongPatch) IsEmpty() bool {
+	return !p.Group.Set && !p.Song.Set && !p.Text.Set && !p.ReleaseDate.Set && !p.Link.Set
+}
@@ -1,10 +1,82 @@
 package model
 
+import "encoding/json"
+
 type Song struct {
-	ID    int64  `json:"ID"`
-	Group string `json:"group"`
-	Song  string `json:"song"`
-	Text  string `json:"text,omitempty"`
+	ID          int64  `json:"ID"`
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	Text        string `json:"text,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty" example:"2006-07-16"`
+	Link        string `json:"link,omitempty" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	// CreatedBy — ID пользователя, добавившего песню; задаётся сервером
+	CreatedBy *int64 `json:"created_by,omitempty"`
+}
+
+type SongFilter struct {
+	ID   int64
+	ByID bool
+	// IDs отбирает песни из списка ID; пустой список не ограничивает выдачу
+	IDs         []int64
+	Group       string
+	Song        string
+	ReleaseFrom string
+	ReleaseTo   string
+	// Match задаёт сравнение группы и названия; пустое значение — MatchContains
+	Match MatchMode
+	// Fuzzy включает нечёткое сравнение группы и названия
+	Fuzzy bool
+	// Lyrics — подстрока текста песни в нижнем регистре
+	Lyrics string
+	// HasText отбирает песни с текстом (true) или без него (false); nil не ограничивает выдачу
+	HasText *bool
+	// Sort — порядок выдачи. Пустой порядок — по id, в нечётком режиме по сходству.
+	// Последним ключом всегда неявно идёт id по возрастанию.
+	Sort []SortKey
+}
+
+// MatchMode задаёт, как сравниваются группа и название с фильтром
+type MatchMode string
+
+const (
+	MatchContains MatchMode = "contains"
+	MatchPrefix   MatchMode = "prefix"
+	MatchExact    MatchMode = "exact"
+)
+
+// Поля песни для выборочного чтения списка (fields, include)
+const (
+	FieldID          = "id"
+	FieldGroup       = "group"
+	FieldSong        = "song"
+	FieldText        = "text"
+	FieldReleaseDate = "release_date"
+	FieldLink        = "link"
+	FieldCreatedBy   = "created_by"
+)
+
+// SongFields — все поля песни, которые можно запросить в fields
+var SongFields = []string{FieldID, FieldGroup, FieldSong, FieldText, FieldReleaseDate, FieldLink, FieldCreatedBy}
+
+// DefaultListFields — поля списка песен по умолчанию: текст возвращается
+// только по include=text, чтобы страницы списка оставались небольшими
+var DefaultListFields = []string{FieldID, FieldGroup, FieldSong, FieldReleaseDate, FieldLink, FieldCreatedBy}
+
+// Поля, по которым можно сортировать список песен
+const (
+	SortByID          = FieldID
+	SortByGroup       = FieldGroup
+	SortBySong        = FieldSong
+	SortByReleaseDate = FieldReleaseDate
+)
+
+// SortFields — допустимые значения SortKey.Field
+var SortFields = []string{SortByID, SortByGroup, SortBySong, SortByReleaseDate}
+
+// SortKey — ключ сортировки списка песен
+type SortKey struct {
+	Field string
+	Desc  bool
 }
 type Response struct {
 	Status  string      `json:"status"`
@@ -12,25 +84,131 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// Problem — тело ошибки в формате RFC 7807 (application/problem+json)
+type Problem struct {
+	Type     string       `json:"type" example:"urn:songs-api:problem:song_not_found"`
+	Title    string       `json:"title" example:"Not Found"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail,omitempty" example:"песня с ID 42 не найдена"`
+	Instance string       `json:"instance,omitempty" example:"/songs/42"`
+	Code     string       `json:"code" example:"song_not_found"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field" example:"release_date"`
+	Message string `json:"message"`
+}
+
+// PageRequest задаёт страницу списка песен: по номеру (Page > 0, прежний
+// режим со смещением) или по курсору. Без номера и курсора читается первая
+// страница в режиме курсоров.
+type PageRequest struct {
+	Page   int
+	Size   int
+	Cursor string
+	// IncludeTotal включает подсчёт общего числа песен отдельным запросом
+	IncludeTotal bool
+}
+
 type SongsResponse struct {
-	Items      []Song `json:"items"`
-	Page       int    `json:"page"`
-	PageSize   int    `json:"page_size"`
-	Total      int    `json:"total"`
-	TotalPages int    `json:"totalPages"`
+	// Items содержит только запрошенные поля (fields), незапрошенные опускаются
+	Items []Song `json:"items"`
+	// Page заполняется в режиме номеров страниц и для первой страницы в режиме курсоров
+	Page     int `json:"page,omitempty"`
+	PageSize int `json:"page_size"`
+	// Total и TotalPages отсутствуют при include_total=false
+	Total      *int   `json:"total,omitempty"`
+	TotalPages *int   `json:"totalPages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// songListItem — Song в списке песен. Клиент мог не запросить группу и
+// название, поэтому в отличие от Song они опускаются, если не выбраны.
+type songListItem struct {
+	ID          int64  `json:"ID"`
+	Group       string `json:"group,omitempty"`
+	Song        string `json:"song,omitempty"`
+	Text        string `json:"text,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	Link        string `json:"link,omitempty"`
+	CreatedBy   *int64 `json:"created_by,omitempty"`
+}
+
+// MarshalJSON выводит элементы списка как songListItem; ответы с одной
+// песней по-прежнему всегда содержат group и song
+func (r SongsResponse) MarshalJSON() ([]byte, error) {
+	type response SongsResponse
+	items := make([]songListItem, len(r.Items))
+	for i, song := range r.Items {
+		items[i] = songListItem(song)
+	}
+	return json.Marshal(struct {
+		response
+		Items []songListItem `json:"items"`
+	}{response(r), items})
+}
+
+// VerseMode задаёт, что считается куплетом при пагинации текста
+type VerseMode string
+
+const (
+	// VerseModeLines — каждая строка текста считается отдельным куплетом
+	VerseModeLines VerseMode = "lines"
+	// VerseModeStanzas — куплеты разделяются пустыми строками
+	VerseModeStanzas VerseMode = "stanzas"
+)
+
+type Stanza struct {
+	Number int      `json:"number"`
+	Label  string   `json:"label,omitempty" example:"Chorus"`
+	Lines  []string `json:"lines"`
 }
 
 type VerseResponse struct {
-	Verses      []string `json:"verses"`
-	VersePage   int      `json:"verse_page"`
-	VerseSize   int      `json:"verse_size"`
-	TotalVerses int      `json:"total_verses"`
-	TotalPages  int      `json:"total_pages"`
+	Mode        VerseMode `json:"mode" enums:"lines,stanzas"`
+	Verses      []string  `json:"verses,omitempty"`
+	Stanzas     []Stanza  `json:"stanzas,omitempty"`
+	VersePage   int       `json:"verse_page"`
+	VerseSize   int       `json:"verse_size"`
+	TotalVerses int       `json:"total_verses"`
+	TotalPages  int       `json:"total_pages"`
 }
 
 type SongVerse struct {
-	SongID int64  `json:"song_id"`
-	Group  string `json:"group"`
-	Song   string `json:"song"`
-	Verse  string `json:"verse"`
+	SongID   int64   `json:"song_id"`
+	Group    string  `json:"group"`
+	Song     string  `json:"song"`
+	Verse    string  `json:"verse"`
+	Rank     float64 `json:"rank"`
+	Headline string  `json:"headline" example:"Another one <b>bites</b> the dust"`
+	// Stanza — номер куплета, в котором найдена строка, Label — его метка
+	Stanza int    `json:"stanza"`
+	Label  string `json:"label,omitempty" example:"Chorus"`
+}
+
+type VerseSearchResponse struct {
+	Items      []SongVerse `json:"items"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	Total      int         `json:"total"`
+	TotalPages int         `json:"total_pages"`
+}
+
+type SearchHit struct {
+	SongID    int64   `json:"song_id"`
+	Group     string  `json:"group"`
+	Song      string  `json:"song"`
+	Score     float64 `json:"score" example:"0.83"`
+	MatchedIn string  `json:"matched_in" enums:"group,song,text"`
+}
+
+type SearchResponse struct {
+	Items       []SearchHit `json:"items"`
+	Page        int         `json:"page"`
+	PageSize    int         `json:"page_size"`
+	Total       int         `json:"total"`
+	TotalPages  int         `json:"total_pages"`
+	Suggestions []string    `json:"suggestions,omitempty"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// User — пользователь API. Роль определяет, какие песни он может изменять.
+type User struct {
+	ID        int64     `json:"id" example:"1"`
+	Name      string    `json:"name" example:"alice"`
+	Role      string    `json:"role" example:"editor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Role struct {
+	Name        string `json:"name" example:"editor"`
+	Description string `json:"description"`
+}
+
+// UserRequest — тело запроса на создание пользователя
+type UserRequest struct {
+	Name string `json:"name" example:"alice"`
+	Role string `json:"role" example:"editor"`
+}
+
+// RoleAssignment — тело запроса на смену роли пользователя
+type RoleAssignment struct {
+	Role string `json:"role" example:"admin"`
+}
@@ -0,0 +1,117 @@
+package musicinfo
+
+import (
+	"awesomeProject/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ErrNotFound возвращается, если внешний сервис не знает о песне
+var ErrNotFound = errors.New("информация о песне не найдена")
+
+// SongDetail описывает ответ внешнего сервиса GET /info
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Client получает дополнительные сведения о песне из внешнего источника
+type Client interface {
+	GetSongDetail(ctx context.Context, group, song string) (SongDetail, error)
+}
+
+type HTTPClient struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	logger     *slog.Logger
+}
+
+func NewHTTPClient(baseURL string, timeout time.Duration, retries int, logger *slog.Logger) *HTTPClient {
+	if retries < 0 {
+		retries = 0
+	}
+	return &HTTPClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		retries:    retries,
+		backoff:    200 * time.Millisecond,
+		logger:     logger,
+	}
+}
+
+func (c *HTTPClient) GetSongDetail(ctx context.Context, group, song string) (SongDetail, error) {
+	params := url.Values{}
+	params.Set("group", group)
+	params.Set("song", song)
+	reqURL := c.baseURL + "/info?" + params.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.backoff * time.Duration(1<<(attempt-1))):
+			case <-ctx.Done():
+				return SongDetail{}, ctx.Err()
+			}
+		}
+		detail, retry, err := c.fetch(ctx, reqURL)
+		if err == nil {
+			return detail, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+		c.logger.WarnContext(ctx, "Music info request failed, retrying", "attempt", attempt+1, "error", err)
+	}
+	return SongDetail{}, lastErr
+}
+
+// fetch выполняет один запрос и сообщает, имеет ли смысл его повторить
+func (c *HTTPClient) fetch(ctx context.Context, reqURL string) (SongDetail, bool, error) {
+	c.logger.DebugContext(ctx, "Requesting song details", "url", config.RedactURL(reqURL))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return SongDetail{}, false, fmt.Errorf("ошибка создания запроса к сервису информации о песнях: %v", err)
+	}
+	// Передаём traceparent, чтобы запрос к внешнему сервису попал в ту же трассу
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Отменённый клиентом запрос повторять бессмысленно
+		if ctx.Err() != nil {
+			return SongDetail{}, false, ctx.Err()
+		}
+		return SongDetail{}, true, fmt.Errorf("ошибка запроса к сервису информации о песнях: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return SongDetail{}, false, ErrNotFound
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return SongDetail{}, true, fmt.Errorf("сервис информации о песнях вернул статус %d", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return SongDetail{}, false, fmt.Errorf("сервис информации о песнях вернул статус %d", resp.StatusCode)
+	}
+
+	var detail SongDetail
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&detail); err != nil {
+		return SongDetail{}, false, fmt.Errorf("ошибка разбора ответа сервиса информации о песнях: %v", err)
+	}
+	return detail, false, nil
+}
@@ -0,0 +1,167 @@
+package musicinfo_test
+
+import (
+	"awesomeProject/internal/musicinfo"
+	"awesomeProject/internal/musicinfo/musicinfotest"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer отвечает статусами из statuses по очереди (последний
+// повторяется) и считает запросы
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		status := statuses[min(n, len(statuses)-1)]
+		if status == http.StatusOK {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"releaseDate":"16.07.2006","text":"Ooh baby","link":"https://example.com"}`))
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func newClient(url string, timeout time.Duration, retries int, backoff time.Duration) *musicinfo.HTTPClient {
+	c := musicinfo.NewHTTPClient(url, timeout, retries, slog.New(slog.DiscardHandler))
+	musicinfo.SetBackoff(c, backoff)
+	return c
+}
+
+func TestGetSongDetailFromFakeServer(t *testing.T) {
+	want := musicinfo.SongDetail{ReleaseDate: "16.07.2006", Text: "Ooh baby", Link: "https://example.com"}
+	srv := musicinfotest.NewFakeServer(map[string]musicinfo.SongDetail{
+		musicinfotest.FakeKey("Muse", "Supermassive Black Hole"): want,
+	})
+	defer srv.Close()
+
+	got, err := newClient(srv.URL+"/", time.Second, 0, 0).GetSongDetail(context.Background(), "MUSE", "supermassive black hole")
+	if err != nil {
+		t.Fatalf("GetSongDetail: %v", err)
+	}
+	if got != want {
+		t.Errorf("GetSongDetail = %+v, want %+v", got, want)
+	}
+}
+
+// TestGetSongDetailRedactsCredentials проверяет, что пароль из URL сервиса
+// не попадает в журнал ни при запросе, ни при повторе
+func TestGetSongDetailRedactsCredentials(t *testing.T) {
+	srv, _ := statusServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	baseURL := strings.Replace(srv.URL, "http://", "http://user:s3cret@", 1)
+	c := musicinfo.NewHTTPClient(baseURL, time.Second, 1, logger)
+	musicinfo.SetBackoff(c, 0)
+
+	if _, err := c.GetSongDetail(context.Background(), "Muse", "Supermassive Black Hole"); err != nil {
+		t.Fatalf("GetSongDetail: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Requesting song details") {
+		t.Fatalf("log has no request entry: %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "s3cret") {
+		t.Errorf("log contains the password: %s", buf.String())
+	}
+}
+
+func TestGetSongDetailStatuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		retries   int
+		wantErr   bool
+		notFound  bool
+		wantCalls int32
+	}{
+		{name: "ok", statuses: []int{200}, retries: 2, wantCalls: 1},
+		{name: "retry 5xx until success", statuses: []int{500, 503, 200}, retries: 2, wantCalls: 3},
+		{name: "5xx exhausts retries", statuses: []int{502}, retries: 2, wantErr: true, wantCalls: 3},
+		{name: "no retry on 4xx", statuses: []int{400, 200}, retries: 2, wantErr: true, wantCalls: 1},
+		{name: "404 is not found", statuses: []int{404, 200}, retries: 2, wantErr: true, notFound: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := statusServer(t, tt.statuses...)
+			_, err := newClient(srv.URL, time.Second, tt.retries, time.Millisecond).GetSongDetail(context.Background(), "Muse", "Uprising")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, musicinfo.ErrNotFound); got != tt.notFound {
+				t.Errorf("errors.Is(err, ErrNotFound) = %v, want %v (err %v)", got, tt.notFound, err)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("requests = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetSongDetailBackoffDoubles(t *testing.T) {
+	srv, calls := statusServer(t, 500, 500, 200)
+	backoff := 30 * time.Millisecond
+
+	start := time.Now()
+	if _, err := newClient(srv.URL, time.Second, 2, backoff).GetSongDetail(context.Background(), "Muse", "Uprising"); err != nil {
+		t.Fatalf("GetSongDetail: %v", err)
+	}
+	// Паузы перед вторым и третьим запросом — backoff и 2*backoff
+	if elapsed := time.Since(start); elapsed < 3*backoff {
+		t.Errorf("elapsed %v, want at least %v", elapsed, 3*backoff)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestGetSongDetailTimeout(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	_, err := newClient(srv.URL, 20*time.Millisecond, 1, time.Millisecond).GetSongDetail(context.Background(), "Muse", "Uprising")
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	// Таймаут HTTP-клиента — временный сбой, запрос повторяется
+	if got := calls.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestGetSongDetailCanceledContextIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := newClient(srv.URL, time.Second, 3, time.Millisecond).GetSongDetail(ctx, "Muse", "Uprising")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
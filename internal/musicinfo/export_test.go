@@ -0,0 +1,8 @@
+package musicinfo
+
+import "time"
+
+// SetBackoff укорачивает паузу между повторами, чтобы тесты не ждали
+func SetBackoff(c *HTTPClient, d time.Duration) {
+	c.backoff = d
+}
@@ -0,0 +1,36 @@
+// Package musicinfotest содержит поддельный сервис информации о песнях для тестов
+package musicinfotest
+
+import (
+	"awesomeProject/internal/musicinfo"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// NewFakeServer поднимает локальный сервер с тем же контрактом, что и внешний
+// сервис. Ключ в details — "группа|песня" в нижнем регистре.
+func NewFakeServer(details map[string]musicinfo.SongDetail) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+		group := r.URL.Query().Get("group")
+		song := r.URL.Query().Get("song")
+		if group == "" || song == "" {
+			http.Error(w, "group and song are required", http.StatusBadRequest)
+			return
+		}
+		detail, ok := details[FakeKey(group, song)]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(detail)
+	})
+	return httptest.NewServer(mux)
+}
+
+func FakeKey(group, song string) string {
+	return strings.ToLower(group) + "|" + strings.ToLower(song)
+}
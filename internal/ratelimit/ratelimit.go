@@ -0,0 +1,121 @@
+// Package ratelimit ограничивает частоту запросов алгоритмом token bucket
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit — параметры корзины: PerMinute токенов пополняется в минуту, не
+// более Burst одновременно. PerMinute <= 0 отключает ограничение.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+func (l Limit) Enabled() bool {
+	return l.PerMinute > 0
+}
+
+// rate возвращает скорость пополнения в токенах за секунду
+func (l Limit) rate() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+// Result — итог попытки взять токен
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset — через сколько корзина наполнится полностью
+	Reset time.Duration
+	// RetryAfter — через сколько появится следующий токен; ноль, если запрос разрешён
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние корзин. Реализация в памяти подходит для одного
+// экземпляра сервиса; для нескольких нужна общая, например на Redis.
+type Store interface {
+	// Take забирает один токен из корзины key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full — момент, когда корзина наполнится; после него её можно удалить
+	full time.Time
+}
+
+// MemoryStore хранит корзины в памяти процесса
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sweepInterval — как часто удаляются корзины, которые успели наполниться:
+// они ничем не отличаются от новых
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	rate, burst := limit.rate(), limit.burst()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	result := Result{Limit: int(burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((burst - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// sweep удаляет корзины, которые к моменту now успели наполниться
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	// 60 в минуту — один токен в секунду, подряд не больше трёх
+	limit := Limit{PerMinute: 60, Burst: 3}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		at   time.Duration // от start
+		key  string
+		want Result
+	}{
+		{"burst 1", 0, "a", Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+		{"burst 2", 0, "a", Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second}},
+		{"burst 3", 0, "a", Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}},
+		{"burst exhausted", 0, "a", Result{Limit: 3, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}},
+		{"other key has its own bucket", 0, "b", Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+		{"partly refilled", 500 * time.Millisecond, "a", Result{Limit: 3, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{"one token refilled", time.Second, "a", Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}},
+		{"refill is capped at burst", time.Hour, "a", Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+	}
+	store := NewMemoryStore()
+	for _, tt := range tests {
+		store.now = func() time.Time { return start.Add(tt.at) }
+		got, err := store.Take(context.Background(), tt.key, limit)
+		if err != nil {
+			t.Fatalf("%s: Take: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: Take = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStoreDisabledAndSweep(t *testing.T) {
+	store := NewMemoryStore()
+	if got, _ := store.Take(context.Background(), "a", Limit{}); !got.Allowed {
+		t.Errorf("disabled limit: Take = %+v, want allowed", got)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return start }
+	store.Take(context.Background(), "a", Limit{PerMinute: 60, Burst: 1})
+	if len(store.buckets) != 1 {
+		t.Fatalf("buckets = %d, want 1", len(store.buckets))
+	}
+	// Через минуту корзина «a» полна, и её удаляет очистка перед следующим запросом
+	store.now = func() time.Time { return start.Add(sweepInterval) }
+	store.Take(context.Background(), "b", Limit{PerMinute: 60, Burst: 1})
+	if _, ok := store.buckets["a"]; ok || len(store.buckets) != 1 {
+		t.Errorf("buckets after sweep = %v, want only b", store.buckets)
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyRepository хранит ключи API. Ключ ищется по хэшу; отозванные ключи не находятся.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error)
+	FindByHash(ctx context.Context, hash string) (model.APIKey, error)
+	List(ctx context.Context) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+}
+
+// SQLAPIKeyRepository работает и с PostgreSQL, и с SQLite: запросы к таблице
+// ключей простые, различаются только параметры ($N против ?N)
+type SQLAPIKeyRepository struct {
+	db     *sql.DB
+	sqlite bool
+}
+
+func NewSQLAPIKeyRepository(db *sql.DB, sqlite bool) *SQLAPIKeyRepository {
+	return &SQLAPIKeyRepository{db: db, sqlite: sqlite}
+}
+
+// query приводит параметры запроса к синтаксису драйвера
+func (r *SQLAPIKeyRepository) query(q string) string {
+	if r.sqlite {
+		return strings.ReplaceAll(q, "$", "?")
+	}
+	return q
+}
+
+const apiKeyColumns = `id, name, prefix, scopes, user_id, created_at, revoked_at`
+
+func scanAPIKey(row rowScanner) (model.APIKey, error) {
+	var key model.APIKey
+	var scopes string
+	var userID sql.NullInt64
+	var revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &scopes, &userID, &key.CreatedAt, &revokedAt); err != nil {
+		return model.APIKey{}, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	if userID.Valid {
+		key.UserID = &userID.Int64
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
+
+func (r *SQLAPIKeyRepository) Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error) {
+	created, err := scanAPIKey(r.db.QueryRowContext(ctx, r.query(
+		`INSERT INTO api_keys (name, prefix, key_hash, scopes, user_id) VALUES ($1, $2, $3, $4, $5) RETURNING `+apiKeyColumns),
+		key.Name, key.Prefix, hash, strings.Join(key.Scopes, " "), key.UserID,
+	))
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("ошибка добавления ключа API: %w", err)
+	}
+	return created, nil
+}
+
+func (r *SQLAPIKeyRepository) FindByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, r.query(
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`), hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.APIKey{}, ErrNotFound
+	}
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("ошибка поиска ключа API: %w", err)
+	}
+	return key, nil
+}
+
+func (r *SQLAPIKeyRepository) List(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ключей API: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *SQLAPIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, r.query(
+		`UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`), id)
+	if err != nil {
+		return fmt.Errorf("ошибка отзыва ключа API: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка проверки результата: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MemoryAPIKeyRepository хранит ключи в памяти процесса
+type MemoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[int64]model.APIKey
+	hashes map[string]int64
+	nextID int64
+}
+
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		keys:   make(map[int64]model.APIKey),
+		hashes: make(map[string]int64),
+		nextID: 1,
+	}
+}
+
+func (r *MemoryAPIKeyRepository) Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.hashes[hash]; ok {
+		return model.APIKey{}, ErrConflict
+	}
+	key.ID = r.nextID
+	key.CreatedAt = time.Now().UTC()
+	key.RevokedAt = nil
+	r.nextID++
+	r.keys[key.ID] = key
+	r.hashes[hash] = key.ID
+	return key, nil
+}
+
+func (r *MemoryAPIKeyRepository) FindByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.hashes[hash]
+	if !ok || r.keys[id].RevokedAt != nil {
+		return model.APIKey{}, ErrNotFound
+	}
+	return r.keys[id], nil
+}
+
+func (r *MemoryAPIKeyRepository) List(ctx context.Context) ([]model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]model.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func (r *MemoryAPIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[id]
+	if !ok || key.RevokedAt != nil {
+		return ErrNotFound
+	}
+	now := time.Now().UTC()
+	key.RevokedAt = &now
+	r.keys[id] = key
+	return nil
+}
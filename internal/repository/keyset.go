@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Position — место строки в упорядоченной выдаче: значения ключей
+// сортировки (кроме id) и сам id, который делает порядок однозначным
+type Position struct {
+	Keys []interface{}
+	ID   int64
+}
+
+// SongPage — результат Seek. First и Last — позиции первой и последней
+// песни страницы; HasMore сообщает, что в направлении чтения есть ещё строки.
+type SongPage struct {
+	Songs   []model.Song
+	First   Position
+	Last    Position
+	HasMore bool
+}
+
+// sortKey — выражение ORDER BY; последним ключом всегда идёт id по возрастанию
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+// songSortKeys возвращает порядок выдачи списка песен: заданный клиентом,
+// а без него в нечётком режиме — по сумме сходства. columns переводит поля
+// сортировки в выражения конкретной СУБД; значения выражений не должны быть
+// NULL, иначе условие seekCondition не выполнится.
+func songSortKeys(sort []model.SortKey, columns map[string]string, scoreExprs []string) []sortKey {
+	if len(sort) == 0 {
+		if len(scoreExprs) == 0 {
+			return nil
+		}
+		return []sortKey{{expr: "(" + strings.Join(scoreExprs, " + ") + ")", desc: true}}
+	}
+	keys := make([]sortKey, 0, len(sort))
+	for _, key := range sort {
+		if key.Field == model.SortByID {
+			// id однозначен, следующие ключи порядок не меняют; по возрастанию
+			// id и так замыкает ORDER BY
+			if key.Desc {
+				keys = append(keys, sortKey{expr: "id", desc: true})
+			}
+			break
+		}
+		keys = append(keys, sortKey{expr: columns[key.Field], desc: key.Desc})
+	}
+	return keys
+}
+
+// orderClause строит ORDER BY; reverse переворачивает порядок для чтения назад
+func orderClause(keys []sortKey, reverse bool) string {
+	parts := make([]string, 0, len(keys)+1)
+	for _, key := range keys {
+		parts = append(parts, key.expr+direction(key.desc, reverse))
+	}
+	parts = append(parts, "id"+direction(false, reverse))
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+func direction(desc, reverse bool) string {
+	if desc != reverse {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// seekCondition строит условие «строго после позиции» в порядке keys, id:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR (k1 = v1 AND ... AND id > vid).
+// При reverse условие обращается в «строго перед». param форматирует номер
+// параметра в синтаксисе драйвера, нумерация продолжается с argIndex.
+func seekCondition(keys []sortKey, pos Position, reverse bool, param func(int) string, argIndex int) (string, []interface{}) {
+	exprs := make([]string, 0, len(keys)+1)
+	descs := make([]bool, 0, len(keys)+1)
+	for _, key := range keys {
+		exprs = append(exprs, key.expr)
+		descs = append(descs, key.desc)
+	}
+	exprs = append(exprs, "id")
+	descs = append(descs, false)
+	values := append(append([]interface{}{}, pos.Keys...), pos.ID)
+
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = param(argIndex + i)
+	}
+
+	var alternatives []string
+	for i := range exprs {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("%s = %s", exprs[j], placeholders[j]))
+		}
+		op := ">"
+		if descs[i] != reverse {
+			op = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %s", exprs[i], op, placeholders[i]))
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return " AND (" + strings.Join(alternatives, " OR ") + ")", values
+}
+
+// keyColumns возвращает выражения ключей для SELECT, чтобы вычислить позиции строк
+func keyColumns(keys []sortKey) string {
+	var columns string
+	for _, key := range keys {
+		columns += ", " + key.expr
+	}
+	return columns
+}
+
+// keyScanner дочитывает значения ключей сортировки после колонок песни
+type keyScanner struct {
+	rowScanner
+	keys []interface{}
+}
+
+func (s keyScanner) Scan(dest ...interface{}) error {
+	ptrs := make([]interface{}, len(s.keys))
+	for i := range s.keys {
+		ptrs[i] = &s.keys[i]
+	}
+	return s.rowScanner.Scan(append(dest, ptrs...)...)
+}
+
+// buildPage переводит прочитанные строки в страницу. Строк читается на одну
+// больше limit, чтобы узнать, есть ли продолжение; при чтении назад строки
+// возвращаются в прямом порядке.
+func buildPage(songs []model.Song, positions []Position, limit int, reverse bool) SongPage {
+	page := SongPage{HasMore: len(songs) > limit}
+	if page.HasMore {
+		songs, positions = songs[:limit], positions[:limit]
+	}
+	if reverse {
+		for i, j := 0, len(songs)-1; i < j; i, j = i+1, j-1 {
+			songs[i], songs[j] = songs[j], songs[i]
+			positions[i], positions[j] = positions[j], positions[i]
+		}
+	}
+	page.Songs = songs
+	if len(positions) > 0 {
+		page.First, page.Last = positions[0], positions[len(positions)-1]
+	}
+	return page
+}
+
+// normalizeKey приводит значение ключа, прочитанное драйвером, к виду,
+// пригодному для JSON и повторной передачи параметром
+func normalizeKey(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+// seekQuery — запрос страницы списка песен; заполняется репозиторием
+// конкретной СУБД, выполняется seekSongs
+type seekQuery struct {
+	columns string
+	where   string
+	args    []interface{}
+	keys    []sortKey
+	param   func(int) string
+	scan    func(rowScanner) (model.Song, error)
+}
+
+func seekSongs(ctx context.Context, db querier, q seekQuery, from *Position, reverse bool, limit int) (SongPage, error) {
+	where, args := q.where, q.args
+	if from != nil {
+		if len(from.Keys) != len(q.keys) {
+			return SongPage{}, ErrInvalidPosition
+		}
+		condition, values := seekCondition(q.keys, *from, reverse, q.param, len(args)+1)
+		where += condition
+		args = append(args, values...)
+	}
+	query := `SELECT ` + q.columns + keyColumns(q.keys) + ` FROM songs` + where +
+		orderClause(q.keys, reverse) + " LIMIT " + q.param(len(args)+1)
+	args = append(args, limit+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return SongPage{}, fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer rows.Close()
+
+	var songs []model.Song
+	var positions []Position
+	for rows.Next() {
+		keys := make([]interface{}, len(q.keys))
+		song, err := q.scan(keyScanner{rows, keys})
+		if err != nil {
+			return SongPage{}, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		for i := range keys {
+			keys[i] = normalizeKey(keys[i])
+		}
+		songs = append(songs, song)
+		positions = append(positions, Position{Keys: keys, ID: song.ID})
+	}
+	if err := rows.Err(); err != nil {
+		return SongPage{}, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return buildPage(songs, positions, limit, reverse), nil
+}
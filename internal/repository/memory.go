@@ -0,0 +1,559 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemorySongRepository хранит песни в памяти процесса. Полнотекстовый
+// и нечёткий поиск реализованы приближённо, без стемминга PostgreSQL.
+type MemorySongRepository struct {
+	mu             sync.RWMutex
+	songs          map[int64]model.Song
+	nextID         int64
+	fuzzyThreshold float64
+}
+
+func NewMemorySongRepository(fuzzyThreshold float64, seed ...model.Song) *MemorySongRepository {
+	r := &MemorySongRepository{
+		songs:          make(map[int64]model.Song),
+		nextID:         1,
+		fuzzyThreshold: fuzzyThreshold,
+	}
+	for _, song := range seed {
+		if song.ID == 0 {
+			song.ID = r.nextID
+		}
+		r.songs[song.ID] = song
+		if song.ID >= r.nextID {
+			r.nextID = song.ID + 1
+		}
+	}
+	return r
+}
+
+// sorted возвращает песни в порядке возрастания ID; вызывается под блокировкой
+func (r *MemorySongRepository) sorted() []model.Song {
+	songs := make([]model.Song, 0, len(r.songs))
+	for _, song := range r.songs {
+		songs = append(songs, song)
+	}
+	sort.Slice(songs, func(i, j int) bool { return songs[i].ID < songs[j].ID })
+	return songs
+}
+
+// match проверяет песню на соответствие фильтру и возвращает оценку
+// нечёткого сходства для сортировки
+func (r *MemorySongRepository) match(song model.Song, filter model.SongFilter) (bool, float64) {
+	if filter.ByID && song.ID != filter.ID {
+		return false, 0
+	}
+	if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, song.ID) {
+		return false, 0
+	}
+	score := 0.0
+	textFilters := []struct {
+		field string
+		value string
+	}{
+		{song.Group, filter.Group},
+		{song.Song, filter.Song},
+	}
+	for _, f := range textFilters {
+		if f.value == "" {
+			continue
+		}
+		matched := matchText(f.field, f.value, filter.Match)
+		if !filter.Fuzzy {
+			if !matched {
+				return false, 0
+			}
+			continue
+		}
+		s := wordSimilarity(f.value, f.field)
+		if !matched && s < r.fuzzyThreshold {
+			return false, 0
+		}
+		score += s
+	}
+	if filter.Lyrics != "" && !matchText(song.Text, filter.Lyrics, model.MatchContains) {
+		return false, 0
+	}
+	if filter.HasText != nil && (song.Text != "") != *filter.HasText {
+		return false, 0
+	}
+	// Даты хранятся в формате ГГГГ-ММ-ДД, поэтому их можно сравнивать как строки
+	if filter.ReleaseFrom != "" && (song.ReleaseDate == "" || song.ReleaseDate < filter.ReleaseFrom) {
+		return false, 0
+	}
+	if filter.ReleaseTo != "" && (song.ReleaseDate == "" || song.ReleaseDate > filter.ReleaseTo) {
+		return false, 0
+	}
+	return true, score
+}
+
+// matchText сравнивает поле песни со значением фильтра в нижнем регистре
+func matchText(field, value string, mode model.MatchMode) bool {
+	field = strings.ToLower(field)
+	switch mode {
+	case model.MatchExact:
+		return field == value
+	case model.MatchPrefix:
+		return strings.HasPrefix(field, value)
+	default:
+		return strings.Contains(field, value)
+	}
+}
+
+type scoredSong struct {
+	song  model.Song
+	score float64
+}
+
+// memoryKey — ключ сортировки, аналог sortKey для хранилища в памяти
+type memoryKey struct {
+	value func(scoredSong) interface{}
+	desc  bool
+}
+
+var memorySortValues = map[string]func(scoredSong) interface{}{
+	model.SortByGroup:       func(m scoredSong) interface{} { return m.song.Group },
+	model.SortBySong:        func(m scoredSong) interface{} { return m.song.Song },
+	model.SortByReleaseDate: func(m scoredSong) interface{} { return m.song.ReleaseDate },
+}
+
+// memorySortKeys повторяет songSortKeys: заданный порядок, а без него в
+// нечётком режиме — по убыванию сходства; id замыкает порядок
+func memorySortKeys(filter model.SongFilter) []memoryKey {
+	if len(filter.Sort) == 0 {
+		if !orderedByScore(filter) {
+			return nil
+		}
+		return []memoryKey{{value: func(m scoredSong) interface{} { return m.score }, desc: true}}
+	}
+	keys := make([]memoryKey, 0, len(filter.Sort))
+	for _, key := range filter.Sort {
+		if key.Field == model.SortByID {
+			if key.Desc {
+				keys = append(keys, memoryKey{value: func(m scoredSong) interface{} { return m.song.ID }, desc: true})
+			}
+			break
+		}
+		keys = append(keys, memoryKey{value: memorySortValues[key.Field], desc: key.Desc})
+	}
+	return keys
+}
+
+// position возвращает позицию песни в порядке выдачи, как её вычислил бы Seek в SQL
+func (m scoredSong) position(keys []memoryKey) Position {
+	pos := Position{ID: m.song.ID}
+	for _, key := range keys {
+		pos.Keys = append(pos.Keys, key.value(m))
+	}
+	return pos
+}
+
+// positionAfter сообщает, идёт ли p строго после from в порядке keys, затем по id
+func positionAfter(p, from Position, keys []memoryKey) bool {
+	for i, key := range keys {
+		if c := compareKeys(p.Keys[i], from.Keys[i]); c != 0 {
+			return (c > 0) != key.desc
+		}
+	}
+	return p.ID > from.ID
+}
+
+// compareKeys сравнивает значения ключей. Числа из курсора после JSON
+// приходят как float64, поэтому числовые ключи сравниваются как float64.
+func compareKeys(a, b interface{}) int {
+	if x, ok := a.(string); ok {
+		y, _ := b.(string)
+		return strings.Compare(x, y)
+	}
+	return cmp.Compare(toFloat(a), toFloat(b))
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+func (r *MemorySongRepository) scored(filter model.SongFilter) []scoredSong {
+	var matched []scoredSong
+	for _, song := range r.sorted() {
+		if ok, score := r.match(song, filter); ok {
+			matched = append(matched, scoredSong{song, score})
+		}
+	}
+	if keys := memorySortKeys(filter); len(keys) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return positionAfter(matched[j].position(keys), matched[i].position(keys), keys)
+		})
+	}
+	return matched
+}
+
+func (r *MemorySongRepository) filter(filter model.SongFilter) []model.Song {
+	matched := r.scored(filter)
+	songs := make([]model.Song, len(matched))
+	for i, m := range matched {
+		songs[i] = m.song
+	}
+	return songs
+}
+
+func (r *MemorySongRepository) Count(ctx context.Context, filter model.SongFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.filter(filter)), nil
+}
+
+func (r *MemorySongRepository) List(ctx context.Context, filter model.SongFilter, fields []string, limit, offset int) ([]model.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	songs := paginate(r.filter(filter), limit, offset)
+	for i := range songs {
+		songs[i] = project(songs[i], fields)
+	}
+	return songs, nil
+}
+
+// project очищает поля песни, не входящие в fields, как это делает
+// selectColumns в SQL; пустой fields — все поля
+func project(song model.Song, fields []string) model.Song {
+	if len(fields) == 0 {
+		return song
+	}
+	projected := model.Song{ID: song.ID}
+	for _, field := range fields {
+		switch field {
+		case model.FieldGroup:
+			projected.Group = song.Group
+		case model.FieldSong:
+			projected.Song = song.Song
+		case model.FieldText:
+			projected.Text = song.Text
+		case model.FieldReleaseDate:
+			projected.ReleaseDate = song.ReleaseDate
+		case model.FieldLink:
+			projected.Link = song.Link
+		case model.FieldCreatedBy:
+			projected.CreatedBy = song.CreatedBy
+		}
+	}
+	return projected
+}
+
+func (r *MemorySongRepository) Seek(ctx context.Context, filter model.SongFilter, fields []string, from *Position, reverse bool, limit int) (SongPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := memorySortKeys(filter)
+	if from != nil && len(from.Keys) != len(keys) {
+		return SongPage{}, ErrInvalidPosition
+	}
+
+	matched := r.scored(filter)
+	if reverse {
+		slices.Reverse(matched)
+	}
+	var songs []model.Song
+	var positions []Position
+	for _, m := range matched {
+		pos := m.position(keys)
+		if from != nil && (reverse && !positionAfter(*from, pos, keys) || !reverse && !positionAfter(pos, *from, keys)) {
+			continue
+		}
+		songs = append(songs, project(m.song, fields))
+		positions = append(positions, pos)
+		if len(songs) > limit {
+			break
+		}
+	}
+	return buildPage(songs, positions, limit, reverse), nil
+}
+
+// orderedByScore сообщает, сортируется ли выдача по сходству: как и в SQL,
+// только при нечётком сравнении хотя бы одного текстового условия
+func orderedByScore(filter model.SongFilter) bool {
+	return filter.Fuzzy && (filter.Group != "" || filter.Song != "")
+}
+
+func (r *MemorySongRepository) Get(ctx context.Context, id int64) (model.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	song, ok := r.songs[id]
+	if !ok {
+		return model.Song{}, ErrNotFound
+	}
+	return song, nil
+}
+
+func (r *MemorySongRepository) Create(ctx context.Context, song model.Song) (model.Song, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	song.ID = r.nextID
+	r.nextID++
+	r.songs[song.ID] = song
+	return song, nil
+}
+
+func (r *MemorySongRepository) Replace(ctx context.Context, id int64, song model.Song) (model.Song, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.songs[id]
+	if !ok {
+		return model.Song{}, ErrNotFound
+	}
+	song.ID = id
+	song.CreatedBy = existing.CreatedBy
+	r.songs[id] = song
+	return song, nil
+}
+
+func (r *MemorySongRepository) Update(ctx context.Context, id int64, patch model.SongPatch) (model.Song, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	song, ok := r.songs[id]
+	if !ok {
+		return model.Song{}, ErrNotFound
+	}
+	fields := []struct {
+		target *string
+		value  model.OptionalString
+	}{
+		{&song.Group, patch.Group},
+		{&song.Song, patch.Song},
+		{&song.Text, patch.Text},
+		{&song.ReleaseDate, patch.ReleaseDate},
+		{&song.Link, patch.Link},
+	}
+	for _, f := range fields {
+		if f.value.Set {
+			// null и пустая строка в памяти неразличимы, как и после COALESCE в PostgreSQL
+			*f.target = f.value.Value
+		}
+	}
+	r.songs[id] = song
+	return song, nil
+}
+
+func (r *MemorySongRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.songs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.songs, id)
+	return nil
+}
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// verseQuery — упрощённый разбор запроса в духе websearch_to_tsquery:
+// все слова обязательны, слова с минусом исключаются
+type verseQuery struct {
+	include []string
+	exclude []string
+}
+
+func parseVerseQuery(query string) verseQuery {
+	var q verseQuery
+	for _, token := range strings.Fields(strings.ToLower(query)) {
+		exclude := strings.HasPrefix(token, "-")
+		for _, w := range words(token) {
+			if w == "or" {
+				continue
+			}
+			if exclude {
+				q.exclude = append(q.exclude, stem(w))
+			} else {
+				q.include = append(q.include, stem(w))
+			}
+		}
+	}
+	return q
+}
+
+// stem грубо отбрасывает окончание, чтобы «песни» находило «песня»
+func stem(w string) string {
+	runes := []rune(w)
+	if len(runes) > 5 {
+		return string(runes[:len(runes)-2])
+	}
+	if len(runes) > 3 {
+		return string(runes[:len(runes)-1])
+	}
+	return w
+}
+
+func matchesTerm(word string, terms []string) bool {
+	for _, t := range terms {
+		if strings.HasPrefix(word, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLine возвращает ранг строки и строку с выделенными совпадениями
+func (q verseQuery) matchLine(line string) (bool, float64, string) {
+	if len(q.include) == 0 {
+		return false, 0, ""
+	}
+	lineWords := words(line)
+	found := make(map[string]bool)
+	hits := 0
+	for _, w := range lineWords {
+		if matchesTerm(w, q.exclude) {
+			return false, 0, ""
+		}
+		for _, t := range q.include {
+			if strings.HasPrefix(w, t) {
+				found[t] = true
+				hits++
+				break
+			}
+		}
+	}
+	if len(found) < len(uniqueTerms(q.include)) {
+		return false, 0, ""
+	}
+
+	headline := wordPattern.ReplaceAllStringFunc(line, func(w string) string {
+		if matchesTerm(strings.ToLower(w), q.include) {
+			return "<b>" + w + "</b>"
+		}
+		return w
+	})
+	return true, float64(hits) / float64(len(lineWords)), headline
+}
+
+// matchSong возвращает все подходящие под запрос строки текста песни
+func (q verseQuery) matchSong(song model.Song) []VerseMatch {
+	var matches []VerseMatch
+	for i, line := range strings.Split(song.Text, "\n") {
+		ok, rank, headline := q.matchLine(line)
+		if !ok {
+			continue
+		}
+		matches = append(matches, VerseMatch{
+			SongVerse: model.SongVerse{
+				SongID:   song.ID,
+				Group:    song.Group,
+				Song:     song.Song,
+				Verse:    line,
+				Rank:     rank,
+				Headline: headline,
+			},
+			Text: song.Text,
+			Line: i + 1,
+		})
+	}
+	return matches
+}
+
+func uniqueTerms(terms []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(terms))
+	for _, t := range terms {
+		set[t] = struct{}{}
+	}
+	return set
+}
+
+func (r *MemorySongRepository) SearchVerses(ctx context.Context, query string, limit, offset int) ([]VerseMatch, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q := parseVerseQuery(query)
+	var matches []VerseMatch
+	for _, song := range r.sorted() {
+		matches = append(matches, q.matchSong(song)...)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Rank > matches[j].Rank })
+	return paginate(matches, limit, offset), len(matches), nil
+}
+
+func (r *MemorySongRepository) Search(ctx context.Context, query string, limit, offset int) ([]model.SearchHit, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hits []model.SearchHit
+	for _, song := range r.sorted() {
+		groupScore := wordSimilarity(query, song.Group)
+		songScore := wordSimilarity(query, song.Song)
+		textScore := wordSimilarity(query, song.Text)
+		score := max(groupScore, songScore, textScore)
+		if score < r.fuzzyThreshold {
+			continue
+		}
+		hits = append(hits, model.SearchHit{
+			SongID:    song.ID,
+			Group:     song.Group,
+			Song:      song.Song,
+			Score:     score,
+			MatchedIn: matchedIn(score, groupScore, songScore),
+		})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return paginate(hits, limit, offset), len(hits), nil
+}
+
+func (r *MemorySongRepository) Suggest(ctx context.Context, query string, limit int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type candidate struct {
+		value string
+		score float64
+	}
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, song := range r.sorted() {
+		for _, value := range []string{song.Group, song.Song} {
+			if seen[value] {
+				continue
+			}
+			seen[value] = true
+			if score := similarity(query, value); score > 0 {
+				candidates = append(candidates, candidate{value, score})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].value < candidates[j].value
+	})
+
+	var suggestions []string
+	for _, c := range paginate(candidates, limit, 0) {
+		suggestions = append(suggestions, c.value)
+	}
+	return suggestions, nil
+}
+
+// paginate возвращает окно items; отрицательные limit или offset дают пустой
+// результат вместо паники при срезе
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 || limit < 0 || offset >= len(items) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
@@ -0,0 +1,14 @@
+// Package migrations содержит миграции, встроенные в бинарный файл
+package migrations
+
+import "embed"
+
+// Postgres — миграции для PostgreSQL
+//
+//go:embed postgres/*.sql
+var Postgres embed.FS
+
+// SQLite — миграции для встроенного хранилища SQLite
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
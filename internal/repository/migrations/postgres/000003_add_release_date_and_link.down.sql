@@ -0,0 +1,3 @@
+ALTER TABLE songs
+    DROP COLUMN link,
+    DROP COLUMN release_date;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE songs
+    ADD COLUMN release_date DATE,
+    ADD COLUMN link TEXT;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS songs_search_vector_idx;
+ALTER TABLE songs DROP COLUMN search_vector;
\ No newline at end of file
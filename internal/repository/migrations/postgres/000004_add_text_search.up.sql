@@ -0,0 +1,8 @@
+-- Текст индексируется сразу в двух конфигурациях, чтобы поиск работал
+-- и по русским, и по английским песням
+ALTER TABLE songs ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        to_tsvector('russian', coalesce(text, '')) || to_tsvector('english', coalesce(text, ''))
+    ) STORED;
+
+CREATE INDEX songs_search_vector_idx ON songs USING GIN (search_vector);
\ No newline at end of file
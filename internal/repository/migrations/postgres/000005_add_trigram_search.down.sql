@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS songs_text_trgm_idx;
+DROP INDEX IF EXISTS songs_song_trgm_idx;
+DROP INDEX IF EXISTS songs_group_trgm_idx;
+DROP EXTENSION IF EXISTS pg_trgm;
\ No newline at end of file
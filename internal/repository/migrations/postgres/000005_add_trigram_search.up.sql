@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Триграммные индексы ускоряют как нечёткий поиск, так и фильтры LIKE '%...%'
+CREATE INDEX songs_group_trgm_idx ON songs USING GIN (LOWER("group") gin_trgm_ops);
+CREATE INDEX songs_song_trgm_idx ON songs USING GIN (LOWER(song) gin_trgm_ops);
+CREATE INDEX songs_text_trgm_idx ON songs USING GIN (LOWER(text) gin_trgm_ops);
\ No newline at end of file
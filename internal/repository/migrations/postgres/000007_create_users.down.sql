@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS songs_created_by_idx;
+ALTER TABLE songs DROP COLUMN IF EXISTS created_by;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS user_id;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,23 @@
+-- Роли: viewer только читает, editor добавляет песни и изменяет свои, admin изменяет всё и управляет пользователями
+CREATE TABLE roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('viewer', 'Чтение песен'),
+    ('editor', 'Добавление песен и изменение своих песен'),
+    ('admin', 'Изменение любых песен и управление пользователями');
+
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL REFERENCES roles (name),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE api_keys ADD COLUMN user_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+
+-- Песни, добавленные до появления пользователей, остаются без владельца и изменяются только администраторами
+ALTER TABLE songs ADD COLUMN created_by INTEGER REFERENCES users (id) ON DELETE SET NULL;
+CREATE INDEX songs_created_by_idx ON songs (created_by);
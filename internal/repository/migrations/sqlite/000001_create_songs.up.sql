@@ -0,0 +1,19 @@
+-- Схема SQLite соответствует итоговой схеме PostgreSQL. Дата выхода хранится
+-- строкой ГГГГ-ММ-ДД: такие строки сравниваются так же, как даты.
+CREATE TABLE songs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    "group" TEXT NOT NULL,
+    song TEXT NOT NULL,
+    text TEXT,
+    release_date TEXT,
+    link TEXT
+);
+
+CREATE INDEX songs_release_date_idx ON songs (release_date);
+
+INSERT INTO songs ("group", song, text) VALUES
+    ('The Beatles', 'Hey Jude', 'Hey Jude, don''t make it bad' || char(10) || 'Take a sad song and make it better' || char(10) || 'Remember to let her into your heart' || char(10) || 'Then you can start to make it better'),
+    ('Queen', 'Bohemian Rhapsody', 'Is this the real life?' || char(10) || 'Is this just fantasy?' || char(10) || 'Caught in a landslide' || char(10) || 'No escape from reality'),
+    ('The Beatles', 'Let It Be', 'When I find myself in times of trouble' || char(10) || 'Mother Mary comes to me' || char(10) || 'Speaking words of wisdom' || char(10) || 'Let it be'),
+    ('Pink Floyd', 'Wish You Were Here', 'So, so you think you can tell' || char(10) || 'Heaven from hell?' || char(10) || 'Blue skies from pain?' || char(10) || 'Can you tell a green field from a cold steel rail?'),
+    ('Queen', 'Another One Bites the Dust', 'Another one bites the dust' || char(10) || 'Another one bites the dust' || char(10) || 'And another one gone, and another one gone' || char(10) || 'Another one bites the dust');
\ No newline at end of file
@@ -0,0 +1,511 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type PostgresSongRepository struct {
+	db *sql.DB
+	// fuzzyThreshold — минимальное триграммное сходство для нечёткого поиска
+	fuzzyThreshold float64
+}
+
+func NewPostgresSongRepository(db *sql.DB, fuzzyThreshold float64) *PostgresSongRepository {
+	return &PostgresSongRepository{db: db, fuzzyThreshold: fuzzyThreshold}
+}
+
+const songColumns = `id, "group", song, COALESCE(text, ''), release_date, COALESCE(link, ''), created_by`
+
+// songFieldColumns — колонки songColumns по порядку: поле песни, выражение
+// и значение-заглушка, которое выбирается вместо поля, если его не запросили
+var songFieldColumns = []struct {
+	field  string
+	column string
+	empty  string
+}{
+	{model.FieldID, `id`, `id`},
+	{model.FieldGroup, `"group"`, `''`},
+	{model.FieldSong, `song`, `''`},
+	{model.FieldText, `COALESCE(text, '')`, `''`},
+	{model.FieldReleaseDate, `release_date`, `NULL`},
+	{model.FieldLink, `COALESCE(link, '')`, `''`},
+	{model.FieldCreatedBy, `created_by`, `NULL`},
+}
+
+// selectColumns возвращает songColumns, в которых незапрошенные поля
+// заменены заглушками: scanSong читает строку как обычно, а СУБД не
+// передаёт лишние данные. id выбирается всегда, пустой fields — все поля.
+// Колонки SQLite совпадают, поэтому функция годится для обоих хранилищ.
+func selectColumns(fields []string) string {
+	columns := make([]string, len(songFieldColumns))
+	for i, c := range songFieldColumns {
+		columns[i] = c.column
+		if len(fields) > 0 && c.field != model.FieldID && !slices.Contains(fields, c.field) {
+			columns[i] = c.empty
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// querier — общее у *sql.DB и *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSong читает строку, выбранную с колонками songColumns
+func scanSong(row rowScanner) (model.Song, error) {
+	var song model.Song
+	var releaseDate sql.NullTime
+	var createdBy sql.NullInt64
+	if err := row.Scan(&song.ID, &song.Group, &song.Song, &song.Text, &releaseDate, &song.Link, &createdBy); err != nil {
+		return model.Song{}, err
+	}
+	if releaseDate.Valid {
+		song.ReleaseDate = releaseDate.Time.Format(model.DateLayout)
+	}
+	if createdBy.Valid {
+		song.CreatedBy = &createdBy.Int64
+	}
+	return song, nil
+}
+
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// uniqueViolation — код ошибки PostgreSQL при нарушении уникальности
+const uniqueViolation = "23505"
+
+// wrapError превращает отсутствие строки в ErrNotFound, нарушение
+// уникальности — в ErrConflict, остальные ошибки оборачивает с описанием
+func wrapError(err error, message string) error {
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == uniqueViolation:
+		return ErrConflict
+	default:
+		return fmt.Errorf("%s: %w", message, err)
+	}
+}
+
+// songSortColumns — выражения полей сортировки. Дата без значения
+// сортируется как пустая строка, то есть раньше любой даты.
+var songSortColumns = map[string]string{
+	model.SortByGroup:       `"group"`,
+	model.SortBySong:        `song`,
+	model.SortByReleaseDate: `COALESCE(to_char(release_date, 'YYYY-MM-DD'), '')`,
+}
+
+// likeEscaper экранирует символы шаблона LIKE, чтобы значение фильтра
+// сравнивалось буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// textCondition возвращает оператор и значение для сравнения колонки с
+// фильтром в режиме mode
+func textCondition(mode model.MatchMode, value string) (string, string) {
+	switch mode {
+	case model.MatchExact:
+		return "=", value
+	case model.MatchPrefix:
+		return "LIKE", likeEscaper.Replace(value) + "%"
+	default:
+		return "LIKE", "%" + likeEscaper.Replace(value) + "%"
+	}
+}
+
+// where строит условие WHERE для фильтра. В нечётком режиме подстрока
+// по-прежнему находится, а опечатки компенсируются оператором <%, который
+// использует триграммные индексы; порог задаёт withTrigramThreshold.
+// Выражения сходства возвращаются для сортировки и вычисляются только для
+// прошедших фильтр строк.
+func (r *PostgresSongRepository) where(filter model.SongFilter) (string, []interface{}, []string) {
+	where := ` WHERE 1=1`
+	var args []interface{}
+	argIndex := 1
+
+	if filter.ByID {
+		where += fmt.Sprintf(" AND id = $%d", argIndex)
+		args = append(args, filter.ID)
+		argIndex++
+	}
+	if len(filter.IDs) > 0 {
+		where += fmt.Sprintf(" AND id = ANY($%d)", argIndex)
+		args = append(args, pq.Array(filter.IDs))
+		argIndex++
+	}
+	var scoreExprs []string
+	textFilters := []struct {
+		column string
+		value  string
+	}{
+		{`"group"`, filter.Group},
+		{`song`, filter.Song},
+	}
+	for _, f := range textFilters {
+		if f.value == "" {
+			continue
+		}
+		op, pattern := textCondition(filter.Match, f.value)
+		condition := fmt.Sprintf(" AND LOWER(%s) %s $%d", f.column, op, argIndex)
+		args = append(args, pattern)
+		argIndex++
+		if filter.Fuzzy {
+			condition = fmt.Sprintf(" AND (LOWER(%s) %s $%d OR $%d <%% LOWER(%s))", f.column, op, argIndex-1, argIndex, f.column)
+			scoreExprs = append(scoreExprs, fmt.Sprintf("word_similarity($%d, LOWER(%s))", argIndex, f.column))
+			args = append(args, f.value)
+			argIndex++
+		}
+		where += condition
+	}
+	if filter.Lyrics != "" {
+		where += fmt.Sprintf(" AND LOWER(text) LIKE $%d", argIndex)
+		_, pattern := textCondition(model.MatchContains, filter.Lyrics)
+		args = append(args, pattern)
+		argIndex++
+	}
+	if filter.HasText != nil {
+		if *filter.HasText {
+			where += " AND COALESCE(text, '') <> ''"
+		} else {
+			where += " AND COALESCE(text, '') = ''"
+		}
+	}
+	if filter.ReleaseFrom != "" {
+		where += fmt.Sprintf(" AND release_date >= $%d", argIndex)
+		args = append(args, filter.ReleaseFrom)
+		argIndex++
+	}
+	if filter.ReleaseTo != "" {
+		where += fmt.Sprintf(" AND release_date <= $%d", argIndex)
+		args = append(args, filter.ReleaseTo)
+	}
+	return where, args, scoreExprs
+}
+
+// withTrigramThreshold выполняет fn в транзакции только для чтения, где
+// pg_trgm.word_similarity_threshold равен fuzzyThreshold: с этим порогом
+// сравнивает оператор <%. Без trigram fn выполняется без транзакции.
+func (r *PostgresSongRepository) withTrigramThreshold(ctx context.Context, trigram bool, fn func(querier) error) error {
+	if !trigram {
+		return fn(r.db)
+	}
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+	// SET не принимает параметры; порог — число из конфигурации, а не ввод клиента
+	threshold := strconv.FormatFloat(r.fuzzyThreshold, 'f', -1, 64)
+	if _, err := tx.ExecContext(ctx, `SET LOCAL pg_trgm.word_similarity_threshold = `+threshold); err != nil {
+		return fmt.Errorf("ошибка установки порога сходства: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresSongRepository) Count(ctx context.Context, filter model.SongFilter) (int, error) {
+	where, args, _ := r.where(filter)
+	var total int
+	err := r.withTrigramThreshold(ctx, filter.Fuzzy, func(q querier) error {
+		if err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM songs`+where, args...).Scan(&total); err != nil {
+			return fmt.Errorf("ошибка подсчёта записей: %w", err)
+		}
+		return nil
+	})
+	return total, err
+}
+
+func (r *PostgresSongRepository) List(ctx context.Context, filter model.SongFilter, fields []string, limit, offset int) ([]model.Song, error) {
+	where, args, scoreExprs := r.where(filter)
+	query := `SELECT ` + selectColumns(fields) + ` FROM songs` + where +
+		orderClause(songSortKeys(filter.Sort, songSortColumns, scoreExprs), false) +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	var songs []model.Song
+	err := r.withTrigramThreshold(ctx, filter.Fuzzy, func(q querier) error {
+		rows, err := q.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("ошибка выполнения запроса: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			song, err := scanSong(rows)
+			if err != nil {
+				return fmt.Errorf("ошибка чтения данных: %w", err)
+			}
+			songs = append(songs, song)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("ошибка чтения строк: %w", err)
+		}
+		return nil
+	})
+	return songs, err
+}
+
+func (r *PostgresSongRepository) Seek(ctx context.Context, filter model.SongFilter, fields []string, from *Position, reverse bool, limit int) (SongPage, error) {
+	where, args, scoreExprs := r.where(filter)
+	var page SongPage
+	err := r.withTrigramThreshold(ctx, filter.Fuzzy, func(q querier) error {
+		var err error
+		page, err = seekSongs(ctx, q, seekQuery{
+			columns: selectColumns(fields),
+			where:   where,
+			args:    args,
+			keys:    songSortKeys(filter.Sort, songSortColumns, scoreExprs),
+			param:   func(n int) string { return fmt.Sprintf("$%d", n) },
+			scan:    scanSong,
+		}, from, reverse, limit)
+		return err
+	})
+	return page, err
+}
+
+func (r *PostgresSongRepository) Get(ctx context.Context, id int64) (model.Song, error) {
+	song, err := scanSong(r.db.QueryRowContext(ctx, `SELECT `+songColumns+` FROM songs WHERE id = $1`, id))
+	if err != nil {
+		return model.Song{}, wrapError(err, "ошибка получения песни")
+	}
+	return song, nil
+}
+
+func (r *PostgresSongRepository) Create(ctx context.Context, song model.Song) (model.Song, error) {
+	created, err := scanSong(r.db.QueryRowContext(ctx,
+		`INSERT INTO songs ("group", song, text, release_date, link, created_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING `+songColumns,
+		song.Group, song.Song, nullIfEmpty(song.Text), nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Link), song.CreatedBy,
+	))
+	if err != nil {
+		return model.Song{}, wrapError(err, "ошибка добавления песни")
+	}
+	return created, nil
+}
+
+func (r *PostgresSongRepository) Replace(ctx context.Context, id int64, song model.Song) (model.Song, error) {
+	replaced, err := scanSong(r.db.QueryRowContext(ctx,
+		`UPDATE songs SET "group" = $1, song = $2, text = $3, release_date = $4, link = $5 WHERE id = $6 RETURNING `+songColumns,
+		song.Group, song.Song, nullIfEmpty(song.Text), nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Link), id,
+	))
+	if err != nil {
+		return model.Song{}, wrapError(err, "ошибка замены песни")
+	}
+	return replaced, nil
+}
+
+func (r *PostgresSongRepository) Update(ctx context.Context, id int64, patch model.SongPatch) (model.Song, error) {
+	query := `UPDATE songs SET `
+	var args []interface{}
+	argIndex := 1
+
+	fields := []struct {
+		column string
+		value  model.OptionalString
+	}{
+		{`"group"`, patch.Group},
+		{`song`, patch.Song},
+		{`text`, patch.Text},
+		{`release_date`, patch.ReleaseDate},
+		{`link`, patch.Link},
+	}
+	for _, field := range fields {
+		if !field.value.Set {
+			continue
+		}
+		query += fmt.Sprintf(`%s = $%d, `, field.column, argIndex)
+		// Пустые строки хранятся как NULL, как и в Create и Replace
+		if field.value.Null {
+			args = append(args, nil)
+		} else {
+			args = append(args, nullIfEmpty(field.value.Value))
+		}
+		argIndex++
+	}
+	query = strings.TrimSuffix(query, ", ")
+	query += fmt.Sprintf(` WHERE id = $%d RETURNING `, argIndex) + songColumns
+	args = append(args, id)
+
+	updated, err := scanSong(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return model.Song{}, wrapError(err, "ошибка обновления песни")
+	}
+	return updated, nil
+}
+
+func (r *PostgresSongRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM songs WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления песни: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка проверки результата: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// searchVersesQuery ищет строки текста, подходящие под запрос в формате
+// websearch_to_tsquery. Песни отбираются по индексу search_vector, затем
+// каждая строка ранжируется отдельно.
+const searchVersesQuery = `
+WITH q AS (
+	SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query
+),
+verses AS (
+	SELECT s.id, s."group", s.song, s.text, v.verse, v.n,
+		to_tsvector('russian', v.verse) || to_tsvector('english', v.verse) AS vector
+	FROM songs s
+	CROSS JOIN q
+	CROSS JOIN LATERAL unnest(string_to_array(s.text, E'\n')) WITH ORDINALITY AS v(verse, n)
+	WHERE s.search_vector @@ q.query
+)
+SELECT verses.id, verses."group", verses.song, verses.text, verses.n, verses.verse,
+	ts_rank(verses.vector, q.query) AS rank,
+	ts_headline('russian', verses.verse, q.query, 'StartSel=<b>, StopSel=</b>, HighlightAll=true') AS headline,
+	COUNT(*) OVER () AS total
+FROM verses
+CROSS JOIN q
+WHERE verses.vector @@ q.query
+ORDER BY rank DESC, verses.id, verses.n
+LIMIT $2 OFFSET $3`
+
+func (r *PostgresSongRepository) SearchVerses(ctx context.Context, query string, limit, offset int) ([]VerseMatch, int, error) {
+	rows, err := r.db.QueryContext(ctx, searchVersesQuery, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска куплетов: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []VerseMatch
+	var total int
+	for rows.Next() {
+		var m VerseMatch
+		if err := rows.Scan(&m.SongID, &m.Group, &m.Song, &m.Text, &m.Line, &m.Verse, &m.Rank, &m.Headline, &total); err != nil {
+			return nil, 0, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return matches, total, nil
+}
+
+// searchQuery ищет песни сразу по группе, названию и тексту. Строки
+// отбираются оператором <% по триграммным индексам (порог задаёт
+// withTrigramThreshold), оценка хита — лучшее из трёх значений
+// word_similarity — считается только для отобранных строк.
+const searchQuery = `
+WITH scored AS (
+	SELECT id, "group", song,
+		word_similarity($1, LOWER("group")) AS group_score,
+		word_similarity($1, LOWER(song)) AS song_score,
+		word_similarity($1, LOWER(COALESCE(text, ''))) AS text_score
+	FROM songs
+	WHERE $1 <% LOWER("group") OR $1 <% LOWER(song) OR $1 <% LOWER(text)
+)
+SELECT id, "group", song, group_score, song_score, text_score,
+	GREATEST(group_score, song_score, text_score) AS score,
+	COUNT(*) OVER () AS total
+FROM scored
+ORDER BY score DESC, id
+LIMIT $2 OFFSET $3`
+
+func (r *PostgresSongRepository) Search(ctx context.Context, query string, limit, offset int) ([]model.SearchHit, int, error) {
+	var hits []model.SearchHit
+	var total int
+	err := r.withTrigramThreshold(ctx, true, func(q querier) error {
+		rows, err := q.QueryContext(ctx, searchQuery, query, limit, offset)
+		if err != nil {
+			return fmt.Errorf("ошибка поиска песен: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var hit model.SearchHit
+			var groupScore, songScore, textScore float64
+			if err := rows.Scan(&hit.SongID, &hit.Group, &hit.Song, &groupScore, &songScore, &textScore, &hit.Score, &total); err != nil {
+				return fmt.Errorf("ошибка чтения данных: %w", err)
+			}
+			hit.MatchedIn = matchedIn(hit.Score, groupScore, songScore)
+			hits = append(hits, hit)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("ошибка чтения строк: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
+// suggestionsQuery подбирает похожие названия групп и песен для подсказки «возможно, вы имели в виду»
+const suggestionsQuery = `
+SELECT value FROM (
+	SELECT DISTINCT "group" AS value FROM songs
+	UNION
+	SELECT DISTINCT song FROM songs
+) candidates
+WHERE similarity(LOWER(value), $1) > 0
+ORDER BY similarity(LOWER(value), $1) DESC, value
+LIMIT $2`
+
+func (r *PostgresSongRepository) Suggest(ctx context.Context, query string, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, suggestionsQuery, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подбора подсказок: %w", err)
+	}
+	defer rows.Close()
+
+	var suggestions []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		suggestions = append(suggestions, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return suggestions, nil
+}
+
+// matchedIn определяет, какое поле дало лучшую оценку
+func matchedIn(score, groupScore, songScore float64) string {
+	switch score {
+	case groupScore:
+		return "group"
+	case songScore:
+		return "song"
+	default:
+		return "text"
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound = errors.New("запись не найдена")
+	ErrConflict = errors.New("запись уже существует")
+	// ErrInvalidPosition — позиция Seek получена для другого порядка выдачи
+	ErrInvalidPosition = errors.New("позиция не соответствует порядку выдачи")
+)
+
+// SongRepository — хранилище песен. Реализации не знают о пагинации
+// страницами и доменных ошибках: этим занимается service.SongService.
+type SongRepository interface {
+	Count(ctx context.Context, filter model.SongFilter) (int, error)
+	// List возвращает песни с полями fields (ID — всегда); остальные поля
+	// остаются пустыми. Пустой fields — все поля.
+	List(ctx context.Context, filter model.SongFilter, fields []string, limit, offset int) ([]model.Song, error)
+	// Seek возвращает до limit песен строго после позиции from (при reverse —
+	// строго перед ней) в порядке выдачи List; nil from — с начала (с конца)
+	Seek(ctx context.Context, filter model.SongFilter, fields []string, from *Position, reverse bool, limit int) (SongPage, error)
+	Get(ctx context.Context, id int64) (model.Song, error)
+	Create(ctx context.Context, song model.Song) (model.Song, error)
+	Replace(ctx context.Context, id int64, song model.Song) (model.Song, error)
+	Update(ctx context.Context, id int64, patch model.SongPatch) (model.Song, error)
+	Delete(ctx context.Context, id int64) error
+	// SearchVerses возвращает строки текстов, подходящие под запрос, и общее число совпадений
+	SearchVerses(ctx context.Context, query string, limit, offset int) ([]VerseMatch, int, error)
+	// Search ищет песни по группе, названию и тексту с учётом опечаток
+	Search(ctx context.Context, query string, limit, offset int) ([]model.SearchHit, int, error)
+	// Suggest подбирает похожие названия групп и песен
+	Suggest(ctx context.Context, query string, limit int) ([]string, error)
+}
+
+// VerseMatch — найденная строка вместе с полным текстом песни и номером
+// строки (с единицы), чтобы сервис мог определить куплет
+type VerseMatch struct {
+	model.SongVerse
+	Text string
+	Line int
+}
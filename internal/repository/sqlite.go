@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Встроенные функции SQLite не приводят к нижнему регистру кириллицу и не
+// умеют считать триграммное сходство, поэтому недостающее регистрируется
+// как пользовательские функции для всех соединений драйвера.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return strings.ToLower(sqliteString(args[0])), nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("similarity", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return similarity(sqliteString(args[0]), sqliteString(args[1])), nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("word_similarity", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return wordSimilarity(sqliteString(args[0]), sqliteString(args[1])), nil
+	})
+}
+
+// sqliteString приводит аргумент пользовательской функции к строке; NULL
+// считается пустой строкой
+func sqliteString(value driver.Value) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type SQLiteSongRepository struct {
+	db *sql.DB
+	// fuzzyThreshold — минимальное триграммное сходство для нечёткого поиска
+	fuzzyThreshold float64
+}
+
+func NewSQLiteSongRepository(db *sql.DB, fuzzyThreshold float64) *SQLiteSongRepository {
+	return &SQLiteSongRepository{db: db, fuzzyThreshold: fuzzyThreshold}
+}
+
+const sqliteSongColumns = `id, "group", song, COALESCE(text, ''), release_date, COALESCE(link, ''), created_by`
+
+// scanSQLiteSong читает строку, выбранную с колонками sqliteSongColumns.
+// Дата выхода хранится строкой ГГГГ-ММ-ДД и возвращается как есть.
+func scanSQLiteSong(row rowScanner) (model.Song, error) {
+	var song model.Song
+	var releaseDate sql.NullString
+	var createdBy sql.NullInt64
+	if err := row.Scan(&song.ID, &song.Group, &song.Song, &song.Text, &releaseDate, &song.Link, &createdBy); err != nil {
+		return model.Song{}, err
+	}
+	song.ReleaseDate = releaseDate.String
+	if createdBy.Valid {
+		song.CreatedBy = &createdBy.Int64
+	}
+	return song, nil
+}
+
+// wrapSQLiteError — аналог wrapError для кодов ошибок SQLite
+func wrapSQLiteError(err error, message string) error {
+	var sqliteErr *sqlite.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return ErrConflict
+	default:
+		return fmt.Errorf("%s: %w", message, err)
+	}
+}
+
+// sqliteSortColumns — аналог songSortColumns; дата хранится строкой ГГГГ-ММ-ДД
+var sqliteSortColumns = map[string]string{
+	model.SortByGroup:       `"group"`,
+	model.SortBySong:        `song`,
+	model.SortByReleaseDate: `COALESCE(release_date, '')`,
+}
+
+// sqliteTextCondition сравнивает колонку с параметром ?n в режиме mode.
+// instr и substr не считают % и _ шаблонами, в отличие от LIKE.
+func sqliteTextCondition(column string, mode model.MatchMode, n int) string {
+	switch mode {
+	case model.MatchExact:
+		return fmt.Sprintf("unicode_lower(%s) = ?%d", column, n)
+	case model.MatchPrefix:
+		return fmt.Sprintf("substr(unicode_lower(%s), 1, length(?%d)) = ?%d", column, n, n)
+	default:
+		return fmt.Sprintf("instr(unicode_lower(%s), ?%d) > 0", column, n)
+	}
+}
+
+// where строит условие WHERE для фильтра по тем же правилам, что и
+// PostgresSongRepository.where; параметры нумеруются (?N), чтобы выражения
+// сходства можно было повторить в ORDER BY
+func (r *SQLiteSongRepository) where(filter model.SongFilter) (string, []interface{}, []string) {
+	where := ` WHERE 1=1`
+	var args []interface{}
+	argIndex := 1
+
+	if filter.ByID {
+		where += fmt.Sprintf(" AND id = ?%d", argIndex)
+		args = append(args, filter.ID)
+		argIndex++
+	}
+	if len(filter.IDs) > 0 {
+		placeholders := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			placeholders[i] = fmt.Sprintf("?%d", argIndex)
+			args = append(args, id)
+			argIndex++
+		}
+		where += " AND id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	var scoreExprs []string
+	textFilters := []struct {
+		column string
+		value  string
+	}{
+		{`"group"`, filter.Group},
+		{`song`, filter.Song},
+	}
+	for _, f := range textFilters {
+		if f.value == "" {
+			continue
+		}
+		condition := sqliteTextCondition(f.column, filter.Match, argIndex)
+		args = append(args, f.value)
+		if !filter.Fuzzy {
+			where += " AND " + condition
+			argIndex++
+			continue
+		}
+		score := fmt.Sprintf("word_similarity(?%d, %s)", argIndex, f.column)
+		where += fmt.Sprintf(" AND (%s OR %s >= ?%d)", condition, score, argIndex+1)
+		args = append(args, r.fuzzyThreshold)
+		argIndex += 2
+		scoreExprs = append(scoreExprs, score)
+	}
+	if filter.Lyrics != "" {
+		where += " AND " + sqliteTextCondition("text", model.MatchContains, argIndex)
+		args = append(args, filter.Lyrics)
+		argIndex++
+	}
+	if filter.HasText != nil {
+		if *filter.HasText {
+			where += " AND COALESCE(text, '') <> ''"
+		} else {
+			where += " AND COALESCE(text, '') = ''"
+		}
+	}
+	if filter.ReleaseFrom != "" {
+		where += fmt.Sprintf(" AND release_date >= ?%d", argIndex)
+		args = append(args, filter.ReleaseFrom)
+		argIndex++
+	}
+	if filter.ReleaseTo != "" {
+		where += fmt.Sprintf(" AND release_date <= ?%d", argIndex)
+		args = append(args, filter.ReleaseTo)
+	}
+	return where, args, scoreExprs
+}
+
+func (r *SQLiteSongRepository) Count(ctx context.Context, filter model.SongFilter) (int, error) {
+	where, args, _ := r.where(filter)
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM songs`+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта записей: %w", err)
+	}
+	return total, nil
+}
+
+func (r *SQLiteSongRepository) List(ctx context.Context, filter model.SongFilter, fields []string, limit, offset int) ([]model.Song, error) {
+	where, args, scoreExprs := r.where(filter)
+	query := `SELECT ` + selectColumns(fields) + ` FROM songs` + where +
+		orderClause(songSortKeys(filter.Sort, sqliteSortColumns, scoreExprs), false) +
+		fmt.Sprintf(" LIMIT ?%d OFFSET ?%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer rows.Close()
+
+	var songs []model.Song
+	for rows.Next() {
+		song, err := scanSQLiteSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return songs, nil
+}
+
+func (r *SQLiteSongRepository) Seek(ctx context.Context, filter model.SongFilter, fields []string, from *Position, reverse bool, limit int) (SongPage, error) {
+	where, args, scoreExprs := r.where(filter)
+	return seekSongs(ctx, r.db, seekQuery{
+		columns: selectColumns(fields),
+		where:   where,
+		args:    args,
+		keys:    songSortKeys(filter.Sort, sqliteSortColumns, scoreExprs),
+		param:   func(n int) string { return fmt.Sprintf("?%d", n) },
+		scan:    scanSQLiteSong,
+	}, from, reverse, limit)
+}
+
+func (r *SQLiteSongRepository) Get(ctx context.Context, id int64) (model.Song, error) {
+	song, err := scanSQLiteSong(r.db.QueryRowContext(ctx, `SELECT `+sqliteSongColumns+` FROM songs WHERE id = ?`, id))
+	if err != nil {
+		return model.Song{}, wrapSQLiteError(err, "ошибка получения песни")
+	}
+	return song, nil
+}
+
+func (r *SQLiteSongRepository) Create(ctx context.Context, song model.Song) (model.Song, error) {
+	created, err := scanSQLiteSong(r.db.QueryRowContext(ctx,
+		`INSERT INTO songs ("group", song, text, release_date, link, created_by) VALUES (?, ?, ?, ?, ?, ?) RETURNING `+sqliteSongColumns,
+		song.Group, song.Song, nullIfEmpty(song.Text), nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Link), song.CreatedBy,
+	))
+	if err != nil {
+		return model.Song{}, wrapSQLiteError(err, "ошибка добавления песни")
+	}
+	return created, nil
+}
+
+func (r *SQLiteSongRepository) Replace(ctx context.Context, id int64, song model.Song) (model.Song, error) {
+	replaced, err := scanSQLiteSong(r.db.QueryRowContext(ctx,
+		`UPDATE songs SET "group" = ?, song = ?, text = ?, release_date = ?, link = ? WHERE id = ? RETURNING `+sqliteSongColumns,
+		song.Group, song.Song, nullIfEmpty(song.Text), nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Link), id,
+	))
+	if err != nil {
+		return model.Song{}, wrapSQLiteError(err, "ошибка замены песни")
+	}
+	return replaced, nil
+}
+
+func (r *SQLiteSongRepository) Update(ctx context.Context, id int64, patch model.SongPatch) (model.Song, error) {
+	query := `UPDATE songs SET `
+	var args []interface{}
+
+	fields := []struct {
+		column string
+		value  model.OptionalString
+	}{
+		{`"group"`, patch.Group},
+		{`song`, patch.Song},
+		{`text`, patch.Text},
+		{`release_date`, patch.ReleaseDate},
+		{`link`, patch.Link},
+	}
+	for _, field := range fields {
+		if !field.value.Set {
+			continue
+		}
+		query += field.column + ` = ?, `
+		// Пустые строки хранятся как NULL, как и в Create и Replace
+		if field.value.Null {
+			args = append(args, nil)
+		} else {
+			args = append(args, nullIfEmpty(field.value.Value))
+		}
+	}
+	query = strings.TrimSuffix(query, ", ")
+	query += ` WHERE id = ? RETURNING ` + sqliteSongColumns
+	args = append(args, id)
+
+	updated, err := scanSQLiteSong(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return model.Song{}, wrapSQLiteError(err, "ошибка обновления песни")
+	}
+	return updated, nil
+}
+
+func (r *SQLiteSongRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM songs WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления песни: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка проверки результата: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SearchVerses отбирает песни, в тексте которых есть все слова запроса,
+// а строки ранжирует так же, как MemorySongRepository: полнотекстового
+// поиска со стеммингом в SQLite нет. Ранг считается по строкам в Go, поэтому
+// LIMIT и OFFSET не переносятся в SQL и все подходящие песни читаются целиком:
+// это приемлемо только для встроенного хранилища разработки и тестов.
+func (r *SQLiteSongRepository) SearchVerses(ctx context.Context, query string, limit, offset int) ([]VerseMatch, int, error) {
+	q := parseVerseQuery(query)
+	if len(q.include) == 0 {
+		return nil, 0, nil
+	}
+	where := ` WHERE text IS NOT NULL`
+	var args []interface{}
+	for _, term := range q.include {
+		where += " AND instr(unicode_lower(text), ?) > 0"
+		args = append(args, term)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteSongColumns+` FROM songs`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска куплетов: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []VerseMatch
+	for rows.Next() {
+		song, err := scanSQLiteSong(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		matches = append(matches, q.matchSong(song)...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Rank > matches[j].Rank })
+	return paginate(matches, limit, offset), len(matches), nil
+}
+
+// sqliteSearchQuery — аналог searchQuery на пользовательских функциях
+const sqliteSearchQuery = `
+WITH scored AS (
+	SELECT id, "group", song,
+		word_similarity(?1, "group") AS group_score,
+		word_similarity(?1, song) AS song_score,
+		word_similarity(?1, COALESCE(text, '')) AS text_score
+	FROM songs
+)
+SELECT id, "group", song, group_score, song_score, text_score,
+	MAX(group_score, song_score, text_score) AS score,
+	COUNT(*) OVER () AS total
+FROM scored
+WHERE MAX(group_score, song_score, text_score) >= ?2
+ORDER BY score DESC, id
+LIMIT ?3 OFFSET ?4`
+
+func (r *SQLiteSongRepository) Search(ctx context.Context, query string, limit, offset int) ([]model.SearchHit, int, error) {
+	rows, err := r.db.QueryContext(ctx, sqliteSearchQuery, query, r.fuzzyThreshold, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска песен: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []model.SearchHit
+	var total int
+	for rows.Next() {
+		var hit model.SearchHit
+		var groupScore, songScore, textScore float64
+		if err := rows.Scan(&hit.SongID, &hit.Group, &hit.Song, &groupScore, &songScore, &textScore, &hit.Score, &total); err != nil {
+			return nil, 0, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		hit.MatchedIn = matchedIn(hit.Score, groupScore, songScore)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return hits, total, nil
+}
+
+const sqliteSuggestionsQuery = `
+SELECT value FROM (
+	SELECT "group" AS value FROM songs
+	UNION
+	SELECT song FROM songs
+)
+WHERE similarity(value, ?1) > 0
+ORDER BY similarity(value, ?1) DESC, value
+LIMIT ?2`
+
+func (r *SQLiteSongRepository) Suggest(ctx context.Context, query string, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, sqliteSuggestionsQuery, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подбора подсказок: %w", err)
+	}
+	defer rows.Close()
+
+	var suggestions []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		suggestions = append(suggestions, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,299 @@
+package repository_test
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/repository/migrations"
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+var testSongs = []model.Song{
+	{Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: "2006-07-16",
+		Text: "[Verse]\nOoh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"},
+	{Group: "Muse", Song: "Uprising", ReleaseDate: "2009-09-07",
+		Text: "Paranoia is in bloom\nThe PR transmissions will resume"},
+	{Group: "Queen", Song: "Bohemian Rhapsody", ReleaseDate: "1975-10-31",
+		Text: "Is this the real life?\nIs this just fantasy?"},
+	{Group: "Queen", Song: "Another One Bites the Dust", ReleaseDate: "1980-08-22"},
+	{Group: "The Beatles", Song: "Let It Be", Link: "https://example.com/let-it-be",
+		Text: "When I find myself in times of trouble\nMother Mary comes to me"},
+}
+
+// newSQLiteRepository создаёт базу SQLite в памяти со встроенными миграциями
+// и без демонстрационных песен
+func newSQLiteRepository(t *testing.T) *repository.SQLiteSongRepository {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Каждое соединение с ":memory:" получает свою базу
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	files, err := fs.Glob(migrations.SQLite, "sqlite/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		migration, err := fs.ReadFile(migrations.SQLite, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(migration)); err != nil {
+			t.Fatalf("migration %s: %v", name, err)
+		}
+	}
+	if _, err := db.Exec(`DELETE FROM songs; DELETE FROM sqlite_sequence WHERE name = 'songs'`); err != nil {
+		t.Fatal(err)
+	}
+	return repository.NewSQLiteSongRepository(db, 0.3)
+}
+
+// forEachRepository выполняет тест над каждой реализацией хранилища,
+// заполненной testSongs с ID от 1 по порядку
+func forEachRepository(t *testing.T, test func(t *testing.T, repo repository.SongRepository)) {
+	backends := []struct {
+		name string
+		new  func(t *testing.T) repository.SongRepository
+	}{
+		{"memory", func(t *testing.T) repository.SongRepository { return repository.NewMemorySongRepository(0.3) }},
+		{"sqlite", func(t *testing.T) repository.SongRepository { return newSQLiteRepository(t) }},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			repo := backend.new(t)
+			for _, song := range testSongs {
+				if _, err := repo.Create(context.Background(), song); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			test(t, repo)
+		})
+	}
+}
+
+func songIDs(songs []model.Song) []int64 {
+	ids := make([]int64, len(songs))
+	for i, song := range songs {
+		ids[i] = song.ID
+	}
+	return ids
+}
+
+func TestSongRepositoryFilter(t *testing.T) {
+	hasText, noText := true, false
+	tests := []struct {
+		name   string
+		filter model.SongFilter
+		want   []int64
+	}{
+		{"no filter", model.SongFilter{}, []int64{1, 2, 3, 4, 5}},
+		{"by id", model.SongFilter{ID: 3, ByID: true}, []int64{3}},
+		{"ids", model.SongFilter{IDs: []int64{5, 1, 42}}, []int64{1, 5}},
+		{"group contains", model.SongFilter{Group: "ee"}, []int64{3, 4}},
+		{"song prefix", model.SongFilter{Song: "up", Match: model.MatchPrefix}, []int64{2}},
+		{"song prefix does not match infix", model.SongFilter{Song: "ris", Match: model.MatchPrefix}, nil},
+		{"group exact", model.SongFilter{Group: "muse", Match: model.MatchExact}, []int64{1, 2}},
+		{"like wildcards are literal", model.SongFilter{Song: "%"}, nil},
+		{"fuzzy group", model.SongFilter{Group: "qeen", Fuzzy: true}, []int64{3, 4}},
+		{"lyrics", model.SongFilter{Lyrics: "fantasy"}, []int64{3}},
+		{"has text", model.SongFilter{HasText: &hasText}, []int64{1, 2, 3, 5}},
+		{"has no text", model.SongFilter{HasText: &noText}, []int64{4}},
+		{"release range", model.SongFilter{ReleaseFrom: "1980-01-01", ReleaseTo: "2008-12-31"}, []int64{1, 4}},
+		{"sort by group desc then song",
+			model.SongFilter{Sort: []model.SortKey{{Field: model.SortByGroup, Desc: true}, {Field: model.SortBySong}}},
+			[]int64{5, 4, 3, 1, 2}},
+		// Песни без даты идут первыми, как NULL в SQLite
+		{"sort by release date", model.SongFilter{Sort: []model.SortKey{{Field: model.SortByReleaseDate}}}, []int64{5, 3, 4, 1, 2}},
+	}
+	forEachRepository(t, func(t *testing.T, repo repository.SongRepository) {
+		ctx := context.Background()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				songs, err := repo.List(ctx, tt.filter, nil, 10, 0)
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				if got := songIDs(songs); !slices.Equal(got, tt.want) {
+					t.Errorf("List ids = %v, want %v", got, tt.want)
+				}
+				total, err := repo.Count(ctx, tt.filter)
+				if err != nil {
+					t.Fatalf("Count: %v", err)
+				}
+				if total != len(tt.want) {
+					t.Errorf("Count = %d, want %d", total, len(tt.want))
+				}
+			})
+		}
+	})
+}
+
+func TestSongRepositorySeek(t *testing.T) {
+	filters := []model.SongFilter{
+		{},
+		{Sort: []model.SortKey{{Field: model.SortByGroup, Desc: true}}},
+		{Sort: []model.SortKey{{Field: model.SortByReleaseDate, Desc: true}}},
+		{Group: "qeen", Fuzzy: true},
+	}
+	forEachRepository(t, func(t *testing.T, repo repository.SongRepository) {
+		ctx := context.Background()
+		for _, filter := range filters {
+			want, err := repo.List(ctx, filter, nil, 10, 0)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+
+			var forward []model.Song
+			var from *repository.Position
+			for {
+				page, err := repo.Seek(ctx, filter, nil, from, false, 2)
+				if err != nil {
+					t.Fatalf("Seek: %v", err)
+				}
+				forward = append(forward, page.Songs...)
+				if !page.HasMore {
+					break
+				}
+				from = &page.Last
+			}
+			if !slices.Equal(songIDs(forward), songIDs(want)) {
+				t.Errorf("%+v: forward ids = %v, want %v", filter, songIDs(forward), songIDs(want))
+			}
+
+			var backward []model.Song
+			from = nil
+			for {
+				page, err := repo.Seek(ctx, filter, nil, from, true, 2)
+				if err != nil {
+					t.Fatalf("Seek: %v", err)
+				}
+				backward = append(page.Songs, backward...)
+				if !page.HasMore {
+					break
+				}
+				from = &page.First
+			}
+			if !slices.Equal(songIDs(backward), songIDs(want)) {
+				t.Errorf("%+v: backward ids = %v, want %v", filter, songIDs(backward), songIDs(want))
+			}
+		}
+
+		_, err := repo.Seek(ctx, model.SongFilter{}, nil, &repository.Position{Keys: []interface{}{"Muse"}, ID: 1}, false, 2)
+		if !errors.Is(err, repository.ErrInvalidPosition) {
+			t.Errorf("Seek with foreign position: err = %v, want ErrInvalidPosition", err)
+		}
+	})
+}
+
+func TestSongRepositoryFields(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo repository.SongRepository) {
+		songs, err := repo.List(context.Background(), model.SongFilter{ID: 5, ByID: true}, []string{model.FieldSong, model.FieldLink}, 10, 0)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		want := model.Song{ID: 5, Song: "Let It Be", Link: "https://example.com/let-it-be"}
+		if len(songs) != 1 || songs[0] != want {
+			t.Errorf("List = %+v, want [%+v]", songs, want)
+		}
+	})
+}
+
+func TestSongRepositoryWrite(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo repository.SongRepository) {
+		ctx := context.Background()
+		created, err := repo.Create(ctx, model.Song{Group: "Muse", Song: "Hysteria"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.ID != 6 {
+			t.Errorf("created id = %d, want 6", created.ID)
+		}
+
+		updated, err := repo.Update(ctx, 1, model.SongPatch{
+			Song: model.OptionalString{Set: true, Value: "Starlight"},
+			Text: model.OptionalString{Set: true, Null: true},
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.Song != "Starlight" || updated.Text != "" || updated.Group != "Muse" || updated.ReleaseDate != "2006-07-16" {
+			t.Errorf("Update = %+v", updated)
+		}
+
+		replaced, err := repo.Replace(ctx, 2, model.Song{Group: "Muse", Song: "Madness"})
+		if err != nil {
+			t.Fatalf("Replace: %v", err)
+		}
+		if got, _ := repo.Get(ctx, 2); got != replaced || got.ReleaseDate != "" {
+			t.Errorf("Get after Replace = %+v, want %+v", got, replaced)
+		}
+
+		if err := repo.Delete(ctx, 3); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.Get(ctx, 3); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get deleted: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, 3); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Delete deleted: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.Update(ctx, 42, model.SongPatch{Song: model.OptionalString{Set: true, Value: "x"}}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update missing: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.Replace(ctx, 42, model.Song{Group: "x", Song: "x"}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Replace missing: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestSongRepositorySearch(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo repository.SongRepository) {
+		ctx := context.Background()
+		verses, total, err := repo.SearchVerses(ctx, "ooh baby", 10, 0)
+		if err != nil {
+			t.Fatalf("SearchVerses: %v", err)
+		}
+		if total != 2 || len(verses) != 2 || verses[0].SongID != 1 || verses[1].SongID != 1 {
+			t.Fatalf("SearchVerses = %+v, total %d, want two lines of song 1", verses, total)
+		}
+		page, total, err := repo.SearchVerses(ctx, "ooh baby", 1, 1)
+		if err != nil {
+			t.Fatalf("SearchVerses: %v", err)
+		}
+		if total != 2 || len(page) != 1 || page[0].Line != verses[1].Line {
+			t.Errorf("SearchVerses second page = %+v, total %d, want line %d", page, total, verses[1].Line)
+		}
+		for _, window := range [][2]int{{10, -1}, {-1, 0}} {
+			page, total, err := repo.SearchVerses(ctx, "ooh baby", window[0], window[1])
+			if err != nil {
+				t.Fatalf("SearchVerses(limit %d, offset %d): %v", window[0], window[1], err)
+			}
+			if total != 2 || len(page) != 0 {
+				t.Errorf("SearchVerses(limit %d, offset %d) = %+v, total %d, want no lines of 2", window[0], window[1], page, total)
+			}
+		}
+
+		hits, total, err := repo.Search(ctx, "bohemian rapsody", 10, 0)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if total == 0 || len(hits) == 0 || hits[0].SongID != 3 || hits[0].MatchedIn != model.FieldSong {
+			t.Errorf("Search = %+v, total %d, want song 3 matched in song first", hits, total)
+		}
+
+		suggestions, err := repo.Suggest(ctx, "beatels", 3)
+		if err != nil {
+			t.Fatalf("Suggest: %v", err)
+		}
+		if len(suggestions) == 0 || suggestions[0] != "The Beatles" {
+			t.Errorf("Suggest = %q, want The Beatles first", suggestions)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Приближённые аналоги функций pg_trgm для хранилищ без этого расширения
+
+// words делит строку на слова так же, как pg_trgm: последовательности букв и цифр
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams возвращает множество триграмм слов; каждое слово дополняется
+// двумя пробелами в начале и одним в конце, как в pg_trgm
+func trigrams(ws []string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, w := range ws {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// similarity — аналог pg_trgm similarity
+func similarity(a, b string) float64 {
+	return jaccard(trigrams(words(a)), trigrams(words(b)))
+}
+
+// wordSimilarity — аналог pg_trgm word_similarity: наибольшее сходство
+// запроса с непрерывным фрагментом слов строки
+func wordSimilarity(query, s string) float64 {
+	qWords := words(query)
+	sWords := words(s)
+	if len(qWords) == 0 || len(sWords) == 0 {
+		return 0
+	}
+	qSet := trigrams(qWords)
+	best := 0.0
+	for size := 1; size <= len(qWords)+1 && size <= len(sWords); size++ {
+		for start := 0; start+size <= len(sWords); start++ {
+			if score := jaccard(qSet, trigrams(sWords[start:start+size])); score > best {
+				best = score
+			}
+		}
+	}
+	return best
+}
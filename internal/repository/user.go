@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UserRepository хранит пользователей и их роли
+type UserRepository interface {
+	List(ctx context.Context) ([]model.User, error)
+	Get(ctx context.Context, id int64) (model.User, error)
+	FindByName(ctx context.Context, name string) (model.User, error)
+	Create(ctx context.Context, user model.User) (model.User, error)
+	SetRole(ctx context.Context, id int64, role string) (model.User, error)
+	// Delete удаляет пользователя и отзывает его ключи API. Песни
+	// пользователя остаются без владельца.
+	Delete(ctx context.Context, id int64) error
+	Roles(ctx context.Context) ([]model.Role, error)
+}
+
+// SQLUserRepository, как и SQLAPIKeyRepository, обслуживает оба драйвера
+type SQLUserRepository struct {
+	db     *sql.DB
+	sqlite bool
+}
+
+func NewSQLUserRepository(db *sql.DB, sqlite bool) *SQLUserRepository {
+	return &SQLUserRepository{db: db, sqlite: sqlite}
+}
+
+func (r *SQLUserRepository) query(q string) string {
+	if r.sqlite {
+		return strings.ReplaceAll(q, "$", "?")
+	}
+	return q
+}
+
+func (r *SQLUserRepository) wrap(err error, message string) error {
+	if r.sqlite {
+		return wrapSQLiteError(err, message)
+	}
+	return wrapError(err, message)
+}
+
+const userColumns = `id, name, role, created_at`
+
+func scanUser(row rowScanner) (model.User, error) {
+	var user model.User
+	if err := row.Scan(&user.ID, &user.Name, &user.Role, &user.CreatedAt); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+func (r *SQLUserRepository) List(ctx context.Context) ([]model.User, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей: %w", err)
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return users, nil
+}
+
+func (r *SQLUserRepository) Get(ctx context.Context, id int64) (model.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, r.query(`SELECT `+userColumns+` FROM users WHERE id = $1`), id))
+	if err != nil {
+		return model.User{}, r.wrap(err, "ошибка получения пользователя")
+	}
+	return user, nil
+}
+
+func (r *SQLUserRepository) FindByName(ctx context.Context, name string) (model.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, r.query(`SELECT `+userColumns+` FROM users WHERE name = $1`), name))
+	if err != nil {
+		return model.User{}, r.wrap(err, "ошибка поиска пользователя")
+	}
+	return user, nil
+}
+
+func (r *SQLUserRepository) Create(ctx context.Context, user model.User) (model.User, error) {
+	created, err := scanUser(r.db.QueryRowContext(ctx, r.query(
+		`INSERT INTO users (name, role) VALUES ($1, $2) RETURNING `+userColumns), user.Name, user.Role))
+	if err != nil {
+		return model.User{}, r.wrap(err, "ошибка добавления пользователя")
+	}
+	return created, nil
+}
+
+func (r *SQLUserRepository) SetRole(ctx context.Context, id int64, role string) (model.User, error) {
+	updated, err := scanUser(r.db.QueryRowContext(ctx, r.query(
+		`UPDATE users SET role = $1 WHERE id = $2 RETURNING `+userColumns), role, id))
+	if err != nil {
+		return model.User{}, r.wrap(err, "ошибка смены роли")
+	}
+	return updated, nil
+}
+
+func (r *SQLUserRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Без отзыва ключи удалённого пользователя продолжили бы работать с ролью viewer
+	if _, err := tx.ExecContext(ctx, r.query(
+		`UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`), id); err != nil {
+		return fmt.Errorf("ошибка отзыва ключей пользователя: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, r.query(`DELETE FROM users WHERE id = $1`), id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления пользователя: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка проверки результата: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLUserRepository) Roles(ctx context.Context) ([]model.Role, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT name, description FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ролей: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []model.Role{}
+	for rows.Next() {
+		var role model.Role
+		if err := rows.Scan(&role.Name, &role.Description); err != nil {
+			return nil, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+	return roles, nil
+}
+
+// MemoryUserRepository хранит пользователей в памяти процесса. Набор ролей
+// совпадает с заполняемым миграцией; с ключами API он не связан, поэтому
+// Delete ничего не отзывает.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[int64]model.User
+	roles  []model.Role
+	nextID int64
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users: make(map[int64]model.User),
+		roles: []model.Role{
+			{Name: "admin", Description: "Изменение любых песен и управление пользователями"},
+			{Name: "editor", Description: "Добавление песен и изменение своих песен"},
+			{Name: "viewer", Description: "Чтение песен"},
+		},
+		nextID: 1,
+	}
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]model.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *MemoryUserRepository) Get(ctx context.Context, id int64) (model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *MemoryUserRepository) FindByName(ctx context.Context, name string) (model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return model.User{}, ErrNotFound
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user model.User) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.users {
+		if existing.Name == user.Name {
+			return model.User{}, ErrConflict
+		}
+	}
+	user.ID = r.nextID
+	user.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.users[user.ID] = user
+	return user, nil
+}
+
+func (r *MemoryUserRepository) SetRole(ctx context.Context, id int64, role string) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+	user.Role = role
+	r.users[id] = user
+	return user, nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *MemoryUserRepository) Roles(ctx context.Context) ([]model.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]model.Role(nil), r.roles...), nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/i18n"
+	"context"
+	"strings"
+	"time"
+)
+
+// Проверки ролей выполняются только для аутентифицированных запросов: при
+// AUTH_ENABLED=false вызывающего в контексте нет и ограничений тоже.
+
+// songOwner проверяет, что вызывающий может добавлять песни, и возвращает
+// ID пользователя, который станет владельцем новой песни
+func songOwner(ctx context.Context) (*int64, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	if principal.Role != auth.RoleEditor && principal.Role != auth.RoleAdmin {
+		return nil, roleRequired(auth.RoleEditor, auth.RoleAdmin)
+	}
+	if principal.UserID == 0 {
+		return nil, nil
+	}
+	return &principal.UserID, nil
+}
+
+// authorizeSongChange разрешает изменять и удалять песню администратору
+// и редактору, который её добавил
+func (s *SongService) authorizeSongChange(ctx context.Context, method string, id int64) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok || principal.Role == auth.RoleAdmin {
+		return nil
+	}
+	if principal.Role != auth.RoleEditor {
+		return roleRequired(auth.RoleEditor, auth.RoleAdmin)
+	}
+
+	start := time.Now()
+	song, err := s.repo.Get(ctx, id)
+	s.observe(method, "Get", start, err)
+	if err != nil {
+		return songError(ctx, err, id)
+	}
+	if song.CreatedBy == nil || *song.CreatedBy != principal.UserID {
+		s.logger.InfoContext(ctx, "Song change denied", "song_id", id, "user_id", principal.UserID)
+		return newError(ErrForbidden, CodeSongNotOwned, i18n.SongNotOwned, id)
+	}
+	return nil
+}
+
+// requireAdmin пропускает только администраторов
+func requireAdmin(ctx context.Context) error {
+	principal, ok := auth.FromContext(ctx)
+	if ok && principal.Role != auth.RoleAdmin {
+		return roleRequired(auth.RoleAdmin)
+	}
+	return nil
+}
+
+func roleRequired(roles ...string) error {
+	return newError(ErrForbidden, CodeRoleRequired, i18n.RoleRequired, strings.Join(roles, ", "))
+}
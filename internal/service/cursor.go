@@ -0,0 +1,81 @@
+package service
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errInvalidCursor = errors.New("некорректный курсор")
+
+// CursorCodec выдаёт клиентам непрозрачные курсоры постраничного чтения.
+// Курсор подписан HMAC-SHA256, поэтому клиент не может подставить в запрос
+// произвольные значения ключей сортировки, и привязан к фильтру: с другими
+// условиями поиска он не принимается.
+type CursorCodec struct {
+	secret []byte
+}
+
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// cursorPayload — содержимое курсора; поля названы коротко, чтобы курсор
+// не раздувал URL
+type cursorPayload struct {
+	Keys     []interface{} `json:"k,omitempty"`
+	ID       int64         `json:"id"`
+	Backward bool          `json:"b,omitempty"`
+	Filter   string        `json:"f"`
+}
+
+func (c *CursorCodec) Encode(pos repository.Position, backward bool, filter model.SongFilter) string {
+	payload, _ := json.Marshal(cursorPayload{Keys: pos.Keys, ID: pos.ID, Backward: backward, Filter: filterFingerprint(filter)})
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// Decode проверяет подпись и фильтр курсора и возвращает позицию и направление
+func (c *CursorCodec) Decode(token string, filter model.SongFilter) (repository.Position, bool, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return repository.Position{}, false, errInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return repository.Position{}, false, errInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return repository.Position{}, false, errInvalidCursor
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return repository.Position{}, false, errInvalidCursor
+	}
+	if p.Filter != filterFingerprint(filter) {
+		return repository.Position{}, false, fmt.Errorf("%w: курсор выдан для другого фильтра", errInvalidCursor)
+	}
+	return repository.Position{Keys: p.Keys, ID: p.ID}, p.Backward, nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// filterFingerprint — короткий отпечаток условий выборки, от которых
+// зависит порядок и состав выдачи
+func filterFingerprint(filter model.SongFilter) string {
+	data, _ := json.Marshal(filter)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec := service.NewCursorCodec([]byte("secret"))
+	filter := model.SongFilter{Group: "muse", Sort: []model.SortKey{{Field: model.SortBySong}}}
+	pos := repository.Position{Keys: []interface{}{"Uprising"}, ID: 2}
+	token := codec.Encode(pos, true, filter)
+
+	got, backward, err := codec.Decode(token, filter)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !backward || got.ID != pos.ID || len(got.Keys) != 1 || got.Keys[0] != "Uprising" {
+		t.Errorf("decoded = %+v, backward %v, want %+v, true", got, backward, pos)
+	}
+
+	payload, signature, _ := strings.Cut(token, ".")
+	forged, _ := base64.RawURLEncoding.DecodeString(payload)
+	forged = []byte(strings.Replace(string(forged), `"id":2`, `"id":3`, 1))
+
+	tests := []struct {
+		name   string
+		token  string
+		filter model.SongFilter
+	}{
+		{"tampered payload", base64.RawURLEncoding.EncodeToString(forged) + "." + signature, filter},
+		{"tampered signature", payload + "." + base64.RawURLEncoding.EncodeToString([]byte("not a signature")), filter},
+		{"other secret", service.NewCursorCodec([]byte("other")).Encode(pos, true, filter), filter},
+		{"other filter", token, model.SongFilter{Group: "queen", Sort: filter.Sort}},
+		{"other sort", token, model.SongFilter{Group: "muse", Sort: []model.SortKey{{Field: model.SortBySong, Desc: true}}}},
+		{"malformed base64", "!!!." + signature, filter},
+		{"malformed signature base64", payload + ".***", filter},
+		{"no signature", payload, filter},
+		{"empty", "", filter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := codec.Decode(tt.token, tt.filter); err == nil {
+				t.Errorf("Decode(%q) succeeded, want error", tt.token)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"awesomeProject/internal/i18n"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Виды ошибок сервиса. Проверяются через errors.Is, текст сообщения
+// для клиента берётся из *Error.
+var (
+	ErrNotFound       = errors.New("не найдено")
+	ErrValidation     = errors.New("ошибка валидации")
+	ErrPageOutOfRange = errors.New("страница вне диапазона")
+	ErrConflict       = errors.New("конфликт данных")
+	ErrTimeout        = errors.New("превышено время ожидания")
+	ErrUnavailable    = errors.New("сервис недоступен")
+	ErrUnauthorized   = errors.New("требуется аутентификация")
+	ErrForbidden      = errors.New("недостаточно прав")
+	ErrRateLimited    = errors.New("превышен лимит запросов")
+)
+
+// Стабильные машиночитаемые коды ошибок. Клиенты опираются на них,
+// поэтому существующие коды менять нельзя.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeMalformedBody    = "malformed_body"
+	CodeNoFieldsToUpdate = "no_fields_to_update"
+	CodeSongNotFound     = "song_not_found"
+	CodeVersesNotFound   = "verses_not_found"
+	CodePageOutOfRange   = "page_out_of_range"
+	CodeConflict         = "conflict"
+	CodeTimeout          = "timeout"
+	CodeUnavailable      = "service_unavailable"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeRoleRequired     = "role_required"
+	CodeSongNotOwned     = "song_not_owned"
+	CodeUserNotFound     = "user_not_found"
+	CodeRateLimited      = "rate_limited"
+)
+
+// Error — ошибка предметной области. Текст сообщения хранится ключом
+// каталога i18n, чтобы обработчик мог перевести его на язык клиента.
+type Error struct {
+	Kind   error
+	Code   string
+	Key    string
+	Args   []interface{}
+	Fields []FieldViolation
+}
+
+// FieldViolation описывает ошибку в конкретном поле запроса
+type FieldViolation struct {
+	Field string
+	Key   string
+	Args  []interface{}
+}
+
+func (e *Error) Error() string {
+	return i18n.Translate(i18n.DefaultLanguage, e.Key, e.Args...)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, code, key string, args ...interface{}) error {
+	return &Error{Kind: kind, Code: code, Key: key, Args: args}
+}
+
+func NewValidationError(code, key string, args ...interface{}) error {
+	return newError(ErrValidation, code, key, args...)
+}
+
+// NewUnauthorizedError сообщает, что учётные данные не переданы или недействительны
+func NewUnauthorizedError() error {
+	return newError(ErrUnauthorized, CodeUnauthorized, i18n.Unauthorized)
+}
+
+// NewForbiddenError сообщает, что у вызывающего нет права scope
+func NewForbiddenError(scope string) error {
+	return newError(ErrForbidden, CodeForbidden, i18n.Forbidden, scope)
+}
+
+// NewRateLimitError сообщает, что клиент исчерпал лимит запросов; повторить
+// можно через retryAfter секунд
+func NewRateLimitError(retryAfter int) error {
+	return newError(ErrRateLimited, CodeRateLimited, i18n.RateLimited, retryAfter)
+}
+
+// NewFieldErrors возвращает ошибку валидации с описанием каждого неверного поля
+func NewFieldErrors(key string, fields ...FieldViolation) error {
+	return &Error{Kind: ErrValidation, Code: CodeValidationFailed, Key: key, Fields: fields}
+}
+
+func NewFieldError(field, key string, args ...interface{}) error {
+	return &Error{
+		Kind:   ErrValidation,
+		Code:   CodeValidationFailed,
+		Key:    key,
+		Args:   args,
+		Fields: []FieldViolation{{Field: field, Key: key, Args: args}},
+	}
+}
+
+// storageError превращает истёкший таймаут операции в ErrTimeout, а отмену
+// запроса и потерю соединения с базой — в ErrUnavailable. Остальные
+// ошибки возвращаются без изменений.
+func storageError(ctx context.Context, err error) error {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	var netErr *net.OpError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		return newError(ErrTimeout, CodeTimeout, i18n.Timeout)
+	case errors.Is(ctx.Err(), context.Canceled), errors.Is(err, context.Canceled),
+		errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.As(err, &netErr):
+		return newError(ErrUnavailable, CodeUnavailable, i18n.Unavailable)
+	default:
+		return err
+	}
+}
@@ -1,29 +1,98 @@
 package service
 
 import (
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/lyrics"
 	"awesomeProject/internal/model"
-	"database/sql"
-	"fmt"
+	"awesomeProject/internal/musicinfo"
+	"awesomeProject/internal/repository"
+	"context"
+	"errors"
 	"log/slog"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("awesomeProject/internal/service")
+
 type SongServiceInterface interface {
-	GetSongs(filterID int64, filterById bool, filterGroup, filterSong string, page, pageSize int) (model.SongsResponse, error)
-	AddSong(song model.Song) (model.Song, error)
-	DeleteSong(id int64) error
-	UpdateSong(id int64, updateSong model.Song) (model.Song, error)
-	GetSongVerses(id int64, versePage, verseSize int) (model.VerseResponse, error)
-	SearchVerses(searchText string) ([]model.SongVerse, error)
+	// GetSongs возвращает страницу песен с полями fields; пустой fields — все поля
+	GetSongs(ctx context.Context, filter model.SongFilter, fields []string, page model.PageRequest) (model.SongsResponse, error)
+	GetSong(ctx context.Context, id int64) (model.Song, error)
+	AddSong(ctx context.Context, song model.Song) (model.Song, error)
+	ReplaceSong(ctx context.Context, id int64, song model.Song) (model.Song, error)
+	DeleteSong(ctx context.Context, id int64) error
+	UpdateSong(ctx context.Context, id int64, patch model.SongPatch) (model.Song, error)
+	GetSongVerses(ctx context.Context, id int64, mode model.VerseMode, versePage, verseSize int) (model.VerseResponse, error)
+	SearchVerses(ctx context.Context, searchText string, page, pageSize int) (model.VerseSearchResponse, error)
+	Search(ctx context.Context, query string, page, pageSize int) (model.SearchResponse, error)
 }
 
 type SongService struct {
-	db     *sql.DB
-	logger *slog.Logger // Используем *slog.Logger
+	repo     repository.SongRepository
+	info     musicinfo.Client // может быть nil, тогда песни не обогащаются
+	cursors  *CursorCodec
+	timeouts Timeouts
+	metrics  Recorder
+	logger   *slog.Logger // Используем *slog.Logger
+}
+
+// Recorder принимает метрики сервиса; реализация для Prometheus — metrics.Metrics
+type Recorder interface {
+	// ObserveQuery записывает длительность обращения к хранилищу из метода сервиса
+	ObserveQuery(method, query string, duration time.Duration, err error)
+	SongCreated()
+	SongDeleted()
+	// EmptySearch отмечает поиск без результатов; kind — вид поиска
+	EmptySearch(kind string)
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) ObserveQuery(string, string, time.Duration, error) {}
+func (nopRecorder) SongCreated()                                      {}
+func (nopRecorder) SongDeleted()                                      {}
+func (nopRecorder) EmptySearch(string)                                {}
+
+// Виды поиска для Recorder.EmptySearch
+const (
+	SearchKindVerses = "verses"
+	SearchKindSongs  = "songs"
+)
+
+// Timeouts ограничивают время обращения к хранилищу по видам операций.
+// Нулевое значение отключает ограничение.
+type Timeouts struct {
+	Read   time.Duration
+	Write  time.Duration
+	Search time.Duration
 }
 
-func NewSongService(db *sql.DB, logger *slog.Logger) *SongService {
-	return &SongService{db: db, logger: logger}
+// NewSongService создаёт сервис; metrics может быть nil, тогда метрики не собираются
+func NewSongService(repo repository.SongRepository, info musicinfo.Client, cursors *CursorCodec, timeouts Timeouts, metrics Recorder, logger *slog.Logger) *SongService {
+	if metrics == nil {
+		metrics = nopRecorder{}
+	}
+	return &SongService{repo: repo, info: info, cursors: cursors, timeouts: timeouts, metrics: metrics, logger: logger}
+}
+
+// observe записывает длительность обращения к хранилищу. Отсутствие записи —
+// штатный результат запроса, а не сбой хранилища.
+func (s *SongService) observe(method, query string, start time.Time, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		err = nil
+	}
+	s.metrics.ObserveQuery(method, query, time.Since(start), err)
+}
+
+// withTimeout ограничивает контекст операции таймаутом d
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
 }
 
 type SongVerse struct {
@@ -33,208 +102,363 @@ type SongVerse struct {
 	Verse  string `json:"verse"`
 }
 
-func (s *SongService) SearchVerses(searchText string) ([]model.SongVerse, error) {
-	s.logger.Debug("Searching verses", "text", searchText)
+// songError переводит ошибки хранилища в ошибки сервиса
+func songError(ctx context.Context, err error, id int64) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return newError(ErrNotFound, CodeSongNotFound, i18n.SongNotFound, id)
+	case errors.Is(err, repository.ErrConflict):
+		return newError(ErrConflict, CodeConflict, i18n.Conflict)
+	default:
+		return storageError(ctx, err)
+	}
+}
+
+func (s *SongService) SearchVerses(ctx context.Context, searchText string, page, pageSize int) (model.VerseSearchResponse, error) {
+	ctx, span := tracer.Start(ctx, "SongService.SearchVerses")
+	defer span.End()
+	s.logger.DebugContext(ctx, "Searching verses", "text", searchText, "page", page, "page_size", pageSize)
 
-	query := `
-	SELECT id, "group", song, text FROM songs WHERE text ILIKE $1
-	`
-	rows, err := s.db.Query(query, "%"+searchText+"%")
+	ctx, cancel := withTimeout(ctx, s.timeouts.Search)
+	defer cancel()
+	start := time.Now()
+	matches, total, err := s.repo.SearchVerses(ctx, searchText, pageSize, (page-1)*pageSize)
+	s.observe("SearchVerses", "SearchVerses", start, err)
 	if err != nil {
-		s.logger.Error("Failed to search verses", "error", err)
+		s.logger.ErrorContext(ctx, "Failed to search verses", "error", err)
+		return model.VerseSearchResponse{}, storageError(ctx, err)
 	}
-	defer rows.Close()
 
-	var results []model.SongVerse
-	for rows.Next() {
-		var song model.Song
-		if err := rows.Scan(&song.ID, &song.Group, &song.Song, &song.Text); err != nil {
-			s.logger.Error("Failed to scan song row", "error", err)
-			return nil, fmt.Errorf("ошибка чтения данных: %v", err)
+	if len(matches) == 0 {
+		// Пустая страница после первой означает, что запрошенная страница
+		// лежит за пределами результатов, а не что ничего не найдено
+		if page > 1 {
+			s.logger.WarnContext(ctx, "Requested search page exceeds total pages", "page", page)
+			return model.VerseSearchResponse{}, newError(ErrPageOutOfRange, CodePageOutOfRange, i18n.PageOutOfRange)
 		}
-		verses := strings.Split(song.Text, "\n")
-		for _, verse := range verses {
-			if strings.Contains(strings.ToLower(verse), strings.ToLower(searchText)) {
-				results = append(results, model.SongVerse{
-					SongID: song.ID,
-					Group:  song.Group,
-					Song:   song.Song,
-					Verse:  verse,
-				})
-			}
-		}
-	}
-	if err := rows.Err(); err != nil {
-		s.logger.Error("Error iterating song rows", "error", err)
-		return nil, fmt.Errorf("ошибка чтения строк: %v", err)
+		s.logger.WarnContext(ctx, "No verses found", "text", searchText)
+		s.metrics.EmptySearch(SearchKindVerses)
+		return model.VerseSearchResponse{}, newError(ErrNotFound, CodeVersesNotFound, i18n.VersesNotFound, searchText)
 	}
 
-	if len(results) == 0 {
-		s.logger.Warn("No verses found", "text", searchText)
-		return nil, fmt.Errorf("куплеты с текстом %q не найдены", searchText)
+	// Куплеты каждой песни разбираются один раз, даже если совпало несколько строк
+	stanzasBySong := make(map[int64][]model.Stanza)
+	lineStanzasBySong := make(map[int64][]int)
+	results := make([]model.SongVerse, 0, len(matches))
+	for _, m := range matches {
+		verse := m.SongVerse
+		if _, ok := stanzasBySong[verse.SongID]; !ok {
+			stanzasBySong[verse.SongID] = lyrics.Parse(m.Text)
+			lineStanzasBySong[verse.SongID] = lyrics.LineStanzas(m.Text)
+		}
+		if lineStanzas := lineStanzasBySong[verse.SongID]; m.Line >= 1 && m.Line <= len(lineStanzas) {
+			verse.Stanza = lineStanzas[m.Line-1]
+			if verse.Stanza > 0 {
+				verse.Label = stanzasBySong[verse.SongID][verse.Stanza-1].Label
+			}
+		}
+		results = append(results, verse)
 	}
 
-	s.logger.Info("Verses found", "count", len(results))
-	return results, nil
+	s.logger.InfoContext(ctx, "Verses found", "count", len(results), "total", total)
+	return model.VerseSearchResponse{
+		Items:      results,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}, nil
 }
-func (s *SongService) GetSongs(filterID int64, filterById bool, filterGroup, filterSong string, page, pageSize int) (model.SongsResponse, error) {
-	s.logger.Debug("Fetching songs", "filter_id", filterID, "filter_group", filterGroup, "filter_song", filterSong)
-	query := `SELECT id, "group", song, text FROM songs WHERE 1=1`
-	countQuery := `SELECT COUNT(*) FROM songs WHERE 1=1`
-	var args []interface{}
-	argIndex := 1
-
-	if filterById {
-		query += fmt.Sprintf(" AND id = $%d", argIndex)
-		countQuery += fmt.Sprintf(" AND id = $%d", argIndex)
-		args = append(args, filterID)
-		argIndex++
-	}
-	if filterGroup != "" {
-		query += fmt.Sprintf(" AND LOWER(\"group\") LIKE $%d", argIndex)
-		countQuery += fmt.Sprintf(" AND LOWER(\"group\") LIKE $%d", argIndex)
-		args = append(args, "%"+filterGroup+"%")
-		argIndex++
-	}
-	if filterSong != "" {
-		query += fmt.Sprintf(" AND LOWER(\"song\") LIKE $%d", argIndex)
-		countQuery += fmt.Sprintf(" AND LOWER(\"song\") LIKE $%d", argIndex)
-		args = append(args, "%"+filterSong+"%")
-		argIndex++
-	}
-
-	var total int
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
+
+const maxSuggestions = 5
+
+func (s *SongService) Search(ctx context.Context, query string, page, pageSize int) (model.SearchResponse, error) {
+	ctx, span := tracer.Start(ctx, "SongService.Search")
+	defer span.End()
+	query = strings.ToLower(query)
+	s.logger.DebugContext(ctx, "Fuzzy search", "query", query, "page", page, "page_size", pageSize)
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Search)
+	defer cancel()
+	start := time.Now()
+	hits, total, err := s.repo.Search(ctx, query, pageSize, (page-1)*pageSize)
+	s.observe("Search", "Search", start, err)
 	if err != nil {
-		s.logger.Error("Failed to count songs", "error", err)
-		return model.SongsResponse{}, fmt.Errorf("ошибка подсчёта записей: %v", err)
+		s.logger.ErrorContext(ctx, "Failed to search songs", "error", err)
+		return model.SearchResponse{}, storageError(ctx, err)
+	}
+	if len(hits) == 0 && page > 1 {
+		return model.SearchResponse{}, newError(ErrPageOutOfRange, CodePageOutOfRange, i18n.PageOutOfRange)
+	}
+	if hits == nil {
+		hits = []model.SearchHit{}
 	}
 
-	totalPages := (total + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
+	resp := model.SearchResponse{
+		Items:      hits,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + pageSize - 1) / pageSize,
 	}
+	if total == 0 {
+		s.metrics.EmptySearch(SearchKindSongs)
+		start = time.Now()
+		resp.Suggestions, err = s.repo.Suggest(ctx, query, maxSuggestions)
+		s.observe("Search", "Suggest", start, err)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to query suggestions", "error", err)
+			return model.SearchResponse{}, storageError(ctx, err)
+		}
+		s.logger.InfoContext(ctx, "Nothing found, suggestions prepared", "query", query, "suggestions", len(resp.Suggestions))
+	}
+	return resp, nil
+}
 
-	if page > totalPages {
-		s.logger.Warn("Requested page exceeds total pages", "page", page, "total_pages", totalPages)
-		return model.SongsResponse{}, fmt.Errorf("запрошенная страница превышает количество страниц")
+func (s *SongService) GetSongs(ctx context.Context, filter model.SongFilter, fields []string, page model.PageRequest) (model.SongsResponse, error) {
+	ctx, span := tracer.Start(ctx, "SongService.GetSongs")
+	defer span.End()
+	s.logger.DebugContext(ctx, "Fetching songs", "filter_id", filter.ID, "filter_ids", filter.IDs, "filter_group", filter.Group, "filter_song", filter.Song,
+		"match", filter.Match, "lyrics", filter.Lyrics, "has_text", filter.HasText,
+		"release_from", filter.ReleaseFrom, "release_to", filter.ReleaseTo, "fuzzy", filter.Fuzzy, "sort", filter.Sort, "fields", fields,
+		"page", page.Page, "cursor", page.Cursor != "", "include_total", page.IncludeTotal)
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	resp := model.SongsResponse{PageSize: page.Size}
+	if page.IncludeTotal {
+		start := time.Now()
+		total, err := s.repo.Count(ctx, filter)
+		s.observe("GetSongs", "Count", start, err)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to count songs", "error", err)
+			return model.SongsResponse{}, storageError(ctx, err)
+		}
+		totalPages := (total + page.Size - 1) / page.Size
+		if totalPages == 0 {
+			totalPages = 1
+		}
+		resp.Total, resp.TotalPages = &total, &totalPages
 	}
 
-	offset := (page - 1) * pageSize
-	query += fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, pageSize, offset)
+	var err error
+	if page.Page > 0 {
+		err = s.songsByOffset(ctx, filter, fields, page, &resp)
+	} else {
+		err = s.songsByCursor(ctx, filter, fields, page, &resp)
+	}
+	if err != nil {
+		return model.SongsResponse{}, err
+	}
+	s.logger.InfoContext(ctx, "Songs fetched successfully", "count", len(resp.Items))
+	return resp, nil
+}
 
-	rows, err := s.db.Query(query, args...)
+// songsByOffset читает страницу по номеру. Номер за пределами выдачи
+// проверяется, только если известно общее число песен.
+func (s *SongService) songsByOffset(ctx context.Context, filter model.SongFilter, fields []string, page model.PageRequest, resp *model.SongsResponse) error {
+	if resp.TotalPages != nil && page.Page > *resp.TotalPages {
+		s.logger.WarnContext(ctx, "Requested page exceeds total pages", "page", page.Page, "total_pages", *resp.TotalPages)
+		return newError(ErrPageOutOfRange, CodePageOutOfRange, i18n.PageOutOfRange)
+	}
+	start := time.Now()
+	songs, err := s.repo.List(ctx, filter, fields, page.Size, (page.Page-1)*page.Size)
+	s.observe("GetSongs", "List", start, err)
 	if err != nil {
-		s.logger.Error("Failed to query songs", "error", err)
-		return model.SongsResponse{}, fmt.Errorf("ошибка выполнения запроса: %v", err)
+		s.logger.ErrorContext(ctx, "Failed to query songs", "error", err)
+		return storageError(ctx, err)
 	}
-	defer rows.Close()
+	resp.Items = songs
+	resp.Page = page.Page
+	return nil
+}
 
-	var songs []model.Song
-	for rows.Next() {
-		var song model.Song
-		if err := rows.Scan(&song.ID, &song.Group, &song.Song, &song.Text); err != nil {
-			s.logger.Error("Failed to scan song row", "error", err)
-			return model.SongsResponse{}, fmt.Errorf("ошибка чтения данных: %v", err)
+// songsByCursor читает страницу после (или перед) позиции из курсора. В
+// отличие от смещения, вставка и удаление песен не сдвигают страницы.
+func (s *SongService) songsByCursor(ctx context.Context, filter model.SongFilter, fields []string, page model.PageRequest, resp *model.SongsResponse) error {
+	var from *repository.Position
+	backward := false
+	if page.Cursor != "" {
+		pos, back, err := s.cursors.Decode(page.Cursor, filter)
+		if err != nil {
+			s.logger.InfoContext(ctx, "Rejected songs cursor", "error", err)
+			return NewFieldError("cursor", i18n.InvalidCursor)
 		}
-		songs = append(songs, song)
+		from, backward = &pos, back
+	} else {
+		resp.Page = 1
 	}
-	if err := rows.Err(); err != nil {
-		s.logger.Error("Error iterating song rows", "error", err)
-		return model.SongsResponse{}, fmt.Errorf("ошибка чтения строк: %v", err)
+
+	start := time.Now()
+	result, err := s.repo.Seek(ctx, filter, fields, from, backward, page.Size)
+	s.observe("GetSongs", "Seek", start, err)
+	if errors.Is(err, repository.ErrInvalidPosition) {
+		return NewFieldError("cursor", i18n.InvalidCursor)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to query songs", "error", err)
+		return storageError(ctx, err)
 	}
 
-	s.logger.Info("Songs fetched successfully", "count", len(songs))
-	return model.SongsResponse{
-		Items:      songs,
-		Page:       page,
-		PageSize:   pageSize,
-		Total:      total,
-		TotalPages: totalPages,
-	}, nil
+	resp.Items = result.Songs
+	if resp.Items == nil {
+		resp.Items = []model.Song{}
+	}
+	if len(result.Songs) == 0 {
+		return nil
+	}
+	// Продолжение в направлении чтения известно из HasMore; в обратном
+	// направлении страница есть всегда, если мы пришли по курсору
+	if backward && result.HasMore || !backward && from != nil {
+		resp.PrevCursor = s.cursors.Encode(result.First, true, filter)
+	}
+	if !backward && result.HasMore || backward {
+		resp.NextCursor = s.cursors.Encode(result.Last, false, filter)
+	}
+	return nil
 }
 
-func (s *SongService) AddSong(song model.Song) (model.Song, error) {
-	err := s.db.QueryRow(
-		`INSERT INTO songs ("group", song) VALUES ($1, $2) RETURNING id`,
-		song.Group, song.Song,
-	).Scan(&song.ID)
+func (s *SongService) GetSong(ctx context.Context, id int64) (model.Song, error) {
+	ctx, span := tracer.Start(ctx, "SongService.GetSong")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	start := time.Now()
+	song, err := s.repo.Get(ctx, id)
+	s.observe("GetSong", "Get", start, err)
 	if err != nil {
-		return model.Song{}, fmt.Errorf("ошибка добавления песни: %v", err)
+		return model.Song{}, songError(ctx, err, id)
 	}
 	return song, nil
 }
-func (s *SongService) DeleteSong(id int64) error {
-	result, err := s.db.Exec(`DELETE FROM songs WHERE id = $1`, id)
-	if err != nil {
-		return fmt.Errorf("ошибка удаления песни: %v", err)
-	}
 
-	rowsAffected, err := result.RowsAffected()
+func (s *SongService) AddSong(ctx context.Context, song model.Song) (model.Song, error) {
+	ctx, span := tracer.Start(ctx, "SongService.AddSong")
+	defer span.End()
+	owner, err := songOwner(ctx)
 	if err != nil {
-		return fmt.Errorf("ошибка проверки результата: %v", err)
+		return model.Song{}, err
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("песня не найдена")
+	song.CreatedBy = owner
+	if song.Text == "" {
+		s.enrichSong(ctx, &song)
 	}
-	return nil
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	start := time.Now()
+	created, err := s.repo.Create(ctx, song)
+	s.observe("AddSong", "Create", start, err)
+	if err != nil {
+		return model.Song{}, songError(ctx, err, 0)
+	}
+	s.metrics.SongCreated()
+	return created, nil
 }
 
-func (s *SongService) UpdateSong(id int64, updateSong model.Song) (model.Song, error) {
-	query := `UPDATE songs SET `
-	var args []interface{}
-	argIndex := 1
-
-	if updateSong.Group != "" {
-		query += fmt.Sprintf(`"group" = $%d, `, argIndex)
-		args = append(args, updateSong.Group)
-		argIndex++
+// enrichSong дополняет песню данными внешнего сервиса. Недоступность сервиса
+// не мешает добавить песню, поэтому ошибки только логируются.
+func (s *SongService) enrichSong(ctx context.Context, song *model.Song) {
+	if s.info == nil {
+		return
 	}
-	if updateSong.Song != "" {
-		query += fmt.Sprintf(`song = $%d, `, argIndex)
-		args = append(args, updateSong.Song)
-		argIndex++
+	detail, err := s.info.GetSongDetail(ctx, song.Group, song.Song)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to fetch song details", "group", song.Group, "song", song.Song, "error", err)
+		return
 	}
-	if len(args) == 0 {
-		return model.Song{}, fmt.Errorf("не указаны поля для обновления")
+	song.Text = detail.Text
+	if song.ReleaseDate == "" && detail.ReleaseDate != "" {
+		if date, err := model.NormalizeDate(detail.ReleaseDate); err != nil {
+			s.logger.WarnContext(ctx, "Music info service returned invalid release date", "release_date", detail.ReleaseDate, "error", err)
+		} else {
+			song.ReleaseDate = date
+		}
 	}
-	query = query[:len(query)-2]
-	query += fmt.Sprintf(` WHERE id = $%d`, argIndex)
-	args = append(args, id)
+	if song.Link == "" {
+		song.Link = detail.Link
+	}
+	s.logger.DebugContext(ctx, "Song enriched from music info service", "group", song.Group, "song", song.Song)
+}
 
-	result, err := s.db.Exec(query, args...)
+func (s *SongService) DeleteSong(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "SongService.DeleteSong")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	if err := s.authorizeSongChange(ctx, "DeleteSong", id); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := s.repo.Delete(ctx, id)
+	s.observe("DeleteSong", "Delete", start, err)
 	if err != nil {
-		return model.Song{}, fmt.Errorf("ошибка обновления песни: %v", err)
+		return songError(ctx, err, id)
+	}
+	s.metrics.SongDeleted()
+	return nil
+}
+
+// ReplaceSong полностью заменяет данные песни: незаполненные поля очищаются
+func (s *SongService) ReplaceSong(ctx context.Context, id int64, song model.Song) (model.Song, error) {
+	ctx, span := tracer.Start(ctx, "SongService.ReplaceSong")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	if err := s.authorizeSongChange(ctx, "ReplaceSong", id); err != nil {
+		return model.Song{}, err
 	}
-	rowsAffected, err := result.RowsAffected()
+	start := time.Now()
+	replaced, err := s.repo.Replace(ctx, id, song)
+	s.observe("ReplaceSong", "Replace", start, err)
 	if err != nil {
-		return model.Song{}, fmt.Errorf("ошибка проверки результата: %v", err)
+		return model.Song{}, songError(ctx, err, id)
+	}
+	return replaced, nil
+}
+
+func (s *SongService) UpdateSong(ctx context.Context, id int64, patch model.SongPatch) (model.Song, error) {
+	ctx, span := tracer.Start(ctx, "SongService.UpdateSong")
+	defer span.End()
+	if patch.IsEmpty() {
+		return model.Song{}, newError(ErrValidation, CodeNoFieldsToUpdate, i18n.NoFieldsToUpdate)
 	}
-	if rowsAffected == 0 {
-		return model.Song{}, fmt.Errorf("песня с заданным ID не найдена")
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	if err := s.authorizeSongChange(ctx, "UpdateSong", id); err != nil {
+		return model.Song{}, err
 	}
-	var updatedSong model.Song
-	err = s.db.QueryRow(`SELECT id, "group", song FROM songs WHERE id = $1`, id).Scan(&updatedSong.ID, &updatedSong.Group, &updatedSong.Song)
+	start := time.Now()
+	updated, err := s.repo.Update(ctx, id, patch)
+	s.observe("UpdateSong", "Update", start, err)
 	if err != nil {
-		return model.Song{}, fmt.Errorf("ошибка получения новой песни: %v", err)
+		return model.Song{}, songError(ctx, err, id)
 	}
-	return updatedSong, nil
+	return updated, nil
 }
 
-func (s *SongService) GetSongVerses(id int64, versePage, verseSize int) (model.VerseResponse, error) {
-	var text string
-	err := s.db.QueryRow(`SELECT text FROM songs WHERE id = $1`, id).Scan(&text)
+func (s *SongService) GetSongVerses(ctx context.Context, id int64, mode model.VerseMode, versePage, verseSize int) (model.VerseResponse, error) {
+	ctx, span := tracer.Start(ctx, "SongService.GetSongVerses")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	queryStart := time.Now()
+	song, err := s.repo.Get(ctx, id)
+	s.observe("GetSongVerses", "Get", queryStart, err)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return model.VerseResponse{}, fmt.Errorf("песня с ID %d не найдена", id)
-		}
-		return model.VerseResponse{}, fmt.Errorf("ошибка получения текста песни: %v", err)
+		return model.VerseResponse{}, songError(ctx, err, id)
 	}
+	text := song.Text
 
-	//Разделение текста на куплеты
-	verses := strings.Split(text, "\n")
-	totalVerses := len(verses)
+	var lines []string
+	var stanzas []model.Stanza
+	var totalVerses int
+	if mode == model.VerseModeLines {
+		lines = lyrics.Lines(text)
+		totalVerses = len(lines)
+	} else {
+		stanzas = lyrics.Parse(text)
+		totalVerses = len(stanzas)
+	}
 
 	totalPages := (totalVerses + verseSize - 1) / verseSize
 	if totalPages == 0 {
@@ -242,7 +466,7 @@ func (s *SongService) GetSongVerses(id int64, versePage, verseSize int) (model.V
 	}
 
 	if versePage > totalPages {
-		return model.VerseResponse{}, fmt.Errorf("запрошенная страница куплетов превышает общее кол-во страниц")
+		return model.VerseResponse{}, newError(ErrPageOutOfRange, CodePageOutOfRange, i18n.VersePageOutOfRange)
 	}
 
 	start := (versePage - 1) * verseSize
@@ -251,13 +475,17 @@ func (s *SongService) GetSongVerses(id int64, versePage, verseSize int) (model.V
 		end = totalVerses
 	}
 
-	pagedVerses := verses[start:end]
-
-	return model.VerseResponse{
-		Verses:      pagedVerses,
+	resp := model.VerseResponse{
+		Mode:        mode,
 		VersePage:   versePage,
 		VerseSize:   verseSize,
 		TotalVerses: totalVerses,
 		TotalPages:  totalPages,
-	}, nil
+	}
+	if mode == model.VerseModeLines {
+		resp.Verses = lines[start:end]
+	} else {
+		resp.Stanzas = stanzas[start:end]
+	}
+	return resp, nil
 }
@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/musicinfo"
+	"awesomeProject/internal/musicinfo/musicinfotest"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func newSongService(info musicinfo.Client) (*service.SongService, *repository.MemorySongRepository) {
+	repo := repository.NewMemorySongRepository(0.3)
+	logger := slog.New(slog.DiscardHandler)
+	return service.NewSongService(repo, info, service.NewCursorCodec([]byte("test")), service.Timeouts{}, nil, logger), repo
+}
+
+func TestAddSongEnrichment(t *testing.T) {
+	srv := musicinfotest.NewFakeServer(map[string]musicinfo.SongDetail{
+		musicinfotest.FakeKey("Muse", "Supermassive Black Hole"): {
+			ReleaseDate: "16.07.2006",
+			Text:        "Ooh baby, don't you know I suffer?",
+			Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+		},
+	})
+	defer srv.Close()
+	info := musicinfo.NewHTTPClient(srv.URL, time.Second, 0, slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		name string
+		song model.Song
+		want model.Song
+	}{
+		{
+			name: "fills text, date and link",
+			song: model.Song{Group: "Muse", Song: "Supermassive Black Hole"},
+			want: model.Song{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby, don't you know I suffer?",
+				ReleaseDate: "2006-07-16", Link: "https://www.youtube.com/watch?v=Xsp3_a-PMTw"},
+		},
+		{
+			name: "keeps client date and link",
+			song: model.Song{Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: "2006-06-19", Link: "https://example.com"},
+			want: model.Song{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby, don't you know I suffer?",
+				ReleaseDate: "2006-06-19", Link: "https://example.com"},
+		},
+		{
+			name: "text from client is not replaced",
+			song: model.Song{Group: "Muse", Song: "Supermassive Black Hole", Text: "own text"},
+			want: model.Song{Group: "Muse", Song: "Supermassive Black Hole", Text: "own text"},
+		},
+		{
+			name: "unknown song is added as is",
+			song: model.Song{Group: "Muse", Song: "Uprising"},
+			want: model.Song{Group: "Muse", Song: "Uprising"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newSongService(info)
+			created, err := svc.AddSong(context.Background(), tt.song)
+			if err != nil {
+				t.Fatalf("AddSong: %v", err)
+			}
+			stored, err := repo.Get(context.Background(), created.ID)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			tt.want.ID = created.ID
+			if stored != tt.want {
+				t.Errorf("stored song = %+v, want %+v", stored, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddSongWithUnavailableMusicInfo(t *testing.T) {
+	// Закрытый сервер: соединение отклоняется, песня добавляется без обогащения
+	srv := musicinfotest.NewFakeServer(nil)
+	srv.Close()
+	info := musicinfo.NewHTTPClient(srv.URL, time.Second, 0, slog.New(slog.DiscardHandler))
+
+	svc, _ := newSongService(info)
+	created, err := svc.AddSong(context.Background(), model.Song{Group: "Muse", Song: "Uprising"})
+	if err != nil {
+		t.Fatalf("AddSong: %v", err)
+	}
+	if created.ID == 0 || created.Text != "" {
+		t.Errorf("created song = %+v, want stored song without text", created)
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"awesomeProject/internal/auth"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/repository"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// maxUserNameLength ограничивает имя пользователя; имя сопоставляется с sub из JWT
+const maxUserNameLength = 200
+
+type UserServiceInterface interface {
+	ListUsers(ctx context.Context) ([]model.User, error)
+	GetUser(ctx context.Context, id int64) (model.User, error)
+	CreateUser(ctx context.Context, user model.User) (model.User, error)
+	AssignRole(ctx context.Context, id int64, role string) (model.User, error)
+	DeleteUser(ctx context.Context, id int64) error
+	ListRoles(ctx context.Context) ([]model.Role, error)
+}
+
+// UserService управляет пользователями и их ролями. Все методы доступны
+// только администраторам.
+type UserService struct {
+	repo     repository.UserRepository
+	timeouts Timeouts
+	metrics  Recorder
+	logger   *slog.Logger
+}
+
+// NewUserService создаёт сервис; metrics может быть nil, тогда метрики не собираются
+func NewUserService(repo repository.UserRepository, timeouts Timeouts, metrics Recorder, logger *slog.Logger) *UserService {
+	if metrics == nil {
+		metrics = nopRecorder{}
+	}
+	return &UserService{repo: repo, timeouts: timeouts, metrics: metrics, logger: logger}
+}
+
+func (s *UserService) observe(method, query string, start time.Time, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		err = nil
+	}
+	s.metrics.ObserveQuery(method, query, time.Since(start), err)
+}
+
+// userError переводит ошибки хранилища в ошибки сервиса
+func userError(ctx context.Context, err error, id int64) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return newError(ErrNotFound, CodeUserNotFound, i18n.UserNotFound, id)
+	case errors.Is(err, repository.ErrConflict):
+		return newError(ErrConflict, CodeConflict, i18n.Conflict)
+	default:
+		return storageError(ctx, err)
+	}
+}
+
+func (s *UserService) ListUsers(ctx context.Context) ([]model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUsers")
+	defer span.End()
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	start := time.Now()
+	users, err := s.repo.List(ctx)
+	s.observe("ListUsers", "List", start, err)
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return users, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id int64) (model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUser")
+	defer span.End()
+	if err := requireAdmin(ctx); err != nil {
+		return model.User{}, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	start := time.Now()
+	user, err := s.repo.Get(ctx, id)
+	s.observe("GetUser", "Get", start, err)
+	if err != nil {
+		return model.User{}, userError(ctx, err, id)
+	}
+	return user, nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, user model.User) (model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+	if err := requireAdmin(ctx); err != nil {
+		return model.User{}, err
+	}
+	user.Name = strings.TrimSpace(user.Name)
+	var fields []FieldViolation
+	if user.Name == "" || len(user.Name) > maxUserNameLength {
+		fields = append(fields, FieldViolation{Field: "name", Key: i18n.UserNameRequired})
+	}
+	if !auth.KnownRole(user.Role) {
+		fields = append(fields, FieldViolation{Field: "role", Key: i18n.InvalidRole, Args: []interface{}{user.Role}})
+	}
+	if len(fields) > 0 {
+		return model.User{}, NewFieldErrors(i18n.InvalidUser, fields...)
+	}
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	start := time.Now()
+	created, err := s.repo.Create(ctx, user)
+	s.observe("CreateUser", "Create", start, err)
+	if err != nil {
+		return model.User{}, userError(ctx, err, 0)
+	}
+	s.logger.InfoContext(ctx, "User created", "user_id", created.ID, "role", created.Role)
+	return created, nil
+}
+
+func (s *UserService) AssignRole(ctx context.Context, id int64, role string) (model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.AssignRole")
+	defer span.End()
+	if err := requireAdmin(ctx); err != nil {
+		return model.User{}, err
+	}
+	if !auth.KnownRole(role) {
+		return model.User{}, NewFieldError("role", i18n.InvalidRole, role)
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	start := time.Now()
+	updated, err := s.repo.SetRole(ctx, id, role)
+	s.observe("AssignRole", "SetRole", start, err)
+	if err != nil {
+		return model.User{}, userError(ctx, err, id)
+	}
+	s.logger.InfoContext(ctx, "User role changed", "user_id", id, "role", role)
+	return updated, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	start := time.Now()
+	err := s.repo.Delete(ctx, id)
+	s.observe("DeleteUser", "Delete", start, err)
+	if err != nil {
+		return userError(ctx, err, id)
+	}
+	s.logger.InfoContext(ctx, "User deleted", "user_id", id)
+	return nil
+}
+
+func (s *UserService) ListRoles(ctx context.Context) ([]model.Role, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListRoles")
+	defer span.End()
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	start := time.Now()
+	roles, err := s.repo.Roles(ctx)
+	s.observe("ListRoles", "Roles", start, err)
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return roles, nil
+}